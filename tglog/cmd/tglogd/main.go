@@ -0,0 +1,42 @@
+// Command tglogd runs a tglog/listener.Listener as a standalone daemon,
+// letting non-Go processes forward logs to a shared Telegram destination
+// without linking against tglog themselves. Configuration is read from the
+// same environment variables as tglog.WithEnv.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/nshiffer/tglog"
+	"github.com/nshiffer/tglog/listener"
+)
+
+func main() {
+	socketPath := flag.String("socket", "/run/tglogd.sock", "Unix socket path to listen on")
+	flag.Parse()
+
+	logger, err := tglog.WithEnv()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tglogd: %v\n", err)
+		os.Exit(1)
+	}
+
+	ln, err := listener.ListenUnix(*socketPath, logger, listener.DefaultListenOptions())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tglogd: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("tglogd: listening on %s\n", ln.Addr())
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGTERM, syscall.SIGINT)
+	<-sigCh
+
+	fmt.Println("tglogd: shutting down")
+	ln.Close()
+	logger.Close() // drains the async queue before exiting
+}
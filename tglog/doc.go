@@ -21,9 +21,15 @@ Basic Usage:
 	logger.Error("Failed to process: %v", err)
 	logger.Fatal("Critical error, shutting down")
 
+	// Or build a structured entry with the fluent API
+	logger.Info().Str("user", "alice").Int("attempt", 3).Msg("login failed")
+
 Features:
 
   - Multiple log levels with distinctive emojis
+  - Fluent, structured logging API (Str/Int/Err/...Msg) alongside the
+    classic Printf-style methods
+  - Pluggable Formatter implementations (HTML, MarkdownV2, JSON, logfmt)
   - Secure environment variable configuration
   - Asynchronous or synchronous operation
   - Timestamps on all messages
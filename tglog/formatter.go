@@ -0,0 +1,190 @@
+package tglog
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Entry is the fully-resolved representation of a single log line, passed to
+// a Formatter to render into the text actually sent to Telegram.
+type Entry struct {
+	// Level is the log severity.
+	Level LogLevel
+	// AppName is the configured Config.AppName, if any.
+	AppName string
+	// Timestamp is the message time already rendered with Config.TimeFormat.
+	Timestamp string
+	// Message is the formatted log message (after Printf-style expansion).
+	Message string
+	// Fields holds any structured key/value pairs attached via the fluent
+	// Event API or WithFields; nil if none were set.
+	Fields map[string]interface{}
+	// Caller is "file:line" of the first call frame outside package tglog;
+	// only populated when the configured Formatter needs it (TemplateFormatter).
+	Caller string
+}
+
+// Formatter renders an Entry into the text to send to Telegram and reports
+// the Telegram parse_mode that text should be sent with.
+type Formatter interface {
+	// Format renders entry as a single message body.
+	Format(entry Entry) string
+	// ParseMode returns the Telegram parse_mode ("HTML", "MarkdownV2", or ""
+	// for plain text) matching what Format produces.
+	ParseMode() string
+}
+
+// sortedFieldKeys returns the keys of fields in a stable, deterministic
+// order so formatted output doesn't jitter between runs.
+func sortedFieldKeys(fields map[string]interface{}) []string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// HTMLFormatter reproduces tglog's original emoji + HTML formatting.
+type HTMLFormatter struct {
+	// Colored wraps level prefixes in <b>/<code> tags (default behavior
+	// when Config.DisableColors is false).
+	Colored bool
+}
+
+// Format implements Formatter.
+func (f HTMLFormatter) Format(e Entry) string {
+	prefix := getLogLevelPrefix(e.Level, f.Colored)
+
+	appName := ""
+	if e.AppName != "" {
+		appName = fmt.Sprintf("<b>[%s]</b> ", e.AppName)
+	}
+
+	msg := fmt.Sprintf("%s%s %s - %s", appName, prefix, e.Timestamp, e.Message)
+	if len(e.Fields) > 0 {
+		var fieldParts []string
+		for _, k := range sortedFieldKeys(e.Fields) {
+			fieldParts = append(fieldParts, fmt.Sprintf("<code>%s=%v</code>", k, e.Fields[k]))
+		}
+		msg += " " + strings.Join(fieldParts, " ")
+	}
+	return msg
+}
+
+// ParseMode implements Formatter.
+func (f HTMLFormatter) ParseMode() string {
+	return "HTML"
+}
+
+// markdownV2Escapes lists every character Telegram's MarkdownV2 parse mode
+// requires to be backslash-escaped outside of entities.
+const markdownV2Escapes = "_*[]()~`>#+-=|{}.!"
+
+// escapeMarkdownV2 escapes s for safe inclusion in a MarkdownV2 message.
+func escapeMarkdownV2(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(markdownV2Escapes, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// MarkdownV2Formatter formats entries for Telegram's MarkdownV2 parse mode,
+// escaping all dynamic content per Telegram's rules.
+type MarkdownV2Formatter struct{}
+
+// Format implements Formatter.
+func (f MarkdownV2Formatter) Format(e Entry) string {
+	prefix := escapeMarkdownV2(fmt.Sprintf("[%s]", levelName(e.Level)))
+
+	var b strings.Builder
+	if e.AppName != "" {
+		fmt.Fprintf(&b, "*%s* ", escapeMarkdownV2(e.AppName))
+	}
+	fmt.Fprintf(&b, "%s %s \\- %s", prefix, escapeMarkdownV2(e.Timestamp), escapeMarkdownV2(e.Message))
+
+	for _, k := range sortedFieldKeys(e.Fields) {
+		fmt.Fprintf(&b, "\n`%s=%s`", escapeMarkdownV2(k), escapeMarkdownV2(fmt.Sprintf("%v", e.Fields[k])))
+	}
+
+	return b.String()
+}
+
+// ParseMode implements Formatter.
+func (f MarkdownV2Formatter) ParseMode() string {
+	return "MarkdownV2"
+}
+
+// JSONFormatter renders entries as a single-line JSON object, useful for
+// sinks or operators that want to parse log output programmatically.
+type JSONFormatter struct{}
+
+type jsonEntry struct {
+	Level   string                 `json:"level"`
+	App     string                 `json:"app,omitempty"`
+	Time    string                 `json:"time"`
+	Message string                 `json:"message"`
+	Fields  map[string]interface{} `json:"fields,omitempty"`
+}
+
+// Format implements Formatter.
+func (f JSONFormatter) Format(e Entry) string {
+	data, err := json.Marshal(jsonEntry{
+		Level:   levelName(e.Level),
+		App:     e.AppName,
+		Time:    e.Timestamp,
+		Message: e.Message,
+		Fields:  e.Fields,
+	})
+	if err != nil {
+		return e.Message
+	}
+	return string(data)
+}
+
+// ParseMode implements Formatter.
+func (f JSONFormatter) ParseMode() string {
+	return ""
+}
+
+// LogfmtFormatter renders entries as space-separated key=value pairs in the
+// style popularized by Heroku/logfmt.
+type LogfmtFormatter struct{}
+
+// Format implements Formatter.
+func (f LogfmtFormatter) Format(e Entry) string {
+	var parts []string
+	parts = append(parts, fmt.Sprintf("level=%s", levelName(e.Level)))
+	if e.AppName != "" {
+		parts = append(parts, fmt.Sprintf("app=%s", logfmtQuote(e.AppName)))
+	}
+	parts = append(parts, fmt.Sprintf("time=%s", logfmtQuote(e.Timestamp)))
+	parts = append(parts, fmt.Sprintf("msg=%s", logfmtQuote(e.Message)))
+
+	for _, k := range sortedFieldKeys(e.Fields) {
+		parts = append(parts, fmt.Sprintf("%s=%s", k, logfmtQuote(fmt.Sprintf("%v", e.Fields[k]))))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// ParseMode implements Formatter.
+func (f LogfmtFormatter) ParseMode() string {
+	return ""
+}
+
+// logfmtQuote wraps s in quotes if it contains a space, tab, double quote
+// or '=', matching common logfmt conventions.
+func logfmtQuote(s string) string {
+	if strings.ContainsAny(s, " \t\"=") {
+		return strconv.Quote(s)
+	}
+	return s
+}
@@ -0,0 +1,61 @@
+package tglog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHTMLFormatterIncludesFields(t *testing.T) {
+	f := HTMLFormatter{Colored: true}
+	msg := f.Format(Entry{
+		Level:     Info,
+		AppName:   "TestApp",
+		Timestamp: "2024-01-01 00:00:00",
+		Message:   "hello",
+		Fields:    map[string]interface{}{"user": "alice"},
+	})
+
+	if !strings.Contains(msg, "hello") || !strings.Contains(msg, "user=alice") {
+		t.Errorf("expected formatted message to include message and fields, got %q", msg)
+	}
+	if f.ParseMode() != "HTML" {
+		t.Errorf("expected parse mode HTML, got %q", f.ParseMode())
+	}
+}
+
+func TestMarkdownV2FormatterEscapes(t *testing.T) {
+	f := MarkdownV2Formatter{}
+	msg := f.Format(Entry{
+		Level:     Error,
+		Timestamp: "2024-01-01",
+		Message:   "failed (bad_input)!",
+	})
+
+	if strings.Contains(msg, "(bad_input)!") {
+		t.Errorf("expected special characters to be escaped, got %q", msg)
+	}
+	if f.ParseMode() != "MarkdownV2" {
+		t.Errorf("expected parse mode MarkdownV2, got %q", f.ParseMode())
+	}
+}
+
+func TestJSONFormatterRoundTrips(t *testing.T) {
+	f := JSONFormatter{}
+	msg := f.Format(Entry{Level: Warning, Message: "disk low", Fields: map[string]interface{}{"pct": 92}})
+
+	if !strings.Contains(msg, `"level":"warning"`) || !strings.Contains(msg, `"message":"disk low"`) {
+		t.Errorf("expected JSON output to contain level and message, got %q", msg)
+	}
+	if f.ParseMode() != "" {
+		t.Errorf("expected empty parse mode for JSON, got %q", f.ParseMode())
+	}
+}
+
+func TestLogfmtFormatterQuotesValuesWithSpaces(t *testing.T) {
+	f := LogfmtFormatter{}
+	msg := f.Format(Entry{Level: Debug, Message: "two words"})
+
+	if !strings.Contains(msg, `msg="two words"`) {
+		t.Errorf("expected quoted message value, got %q", msg)
+	}
+}
@@ -2,14 +2,14 @@ package tglog
 
 import (
 	"fmt"
-	"os"
 )
 
 // Simple creates a new logger with a minimal configuration using environment variables
 // It looks for TELEGRAM_BOT_TOKEN and TELEGRAM_CHAT_ID environment variables
 func Simple(appName string) (*Logger, error) {
-	botToken := os.Getenv("TELEGRAM_BOT_TOKEN")
-	chatID := os.Getenv("TELEGRAM_CHAT_ID")
+	config := DefaultConfig()
+	botToken := getEnv(config.EnvLookup, "TELEGRAM_BOT_TOKEN", "")
+	chatID := getEnv(config.EnvLookup, "TELEGRAM_CHAT_ID", "")
 
 	if botToken == "" {
 		return nil, fmt.Errorf("TELEGRAM_BOT_TOKEN environment variable not set")
@@ -19,7 +19,6 @@ func Simple(appName string) (*Logger, error) {
 		return nil, fmt.Errorf("TELEGRAM_CHAT_ID environment variable not set")
 	}
 
-	config := DefaultConfig()
 	config.BotToken = botToken
 	config.ChatID = chatID
 	config.AppName = appName
@@ -63,13 +62,16 @@ func GetLogLevelFromString(level string) LogLevel {
 // - TELEGRAM_DISABLE_COLORS: whether to disable colors (default: "false")
 // - TELEGRAM_TIME_FORMAT: format for timestamps (default: "2006-01-02 15:04:05")
 func WithEnv() (*Logger, error) {
-	botToken := os.Getenv("TELEGRAM_BOT_TOKEN")
-	chatID := os.Getenv("TELEGRAM_CHAT_ID")
-	logLevel := os.Getenv("TELEGRAM_LOG_LEVEL")
-	appName := os.Getenv("TELEGRAM_APP_NAME")
-	async := os.Getenv("TELEGRAM_ASYNC")
-	disableColors := os.Getenv("TELEGRAM_DISABLE_COLORS")
-	timeFormat := os.Getenv("TELEGRAM_TIME_FORMAT")
+	config := DefaultConfig()
+	lookup := config.EnvLookup
+
+	botToken := getEnv(lookup, "TELEGRAM_BOT_TOKEN", "")
+	chatID := getEnv(lookup, "TELEGRAM_CHAT_ID", "")
+	logLevel := getEnv(lookup, "TELEGRAM_LOG_LEVEL", "")
+	appName := getEnv(lookup, "TELEGRAM_APP_NAME", "")
+	async := getEnv(lookup, "TELEGRAM_ASYNC", "")
+	disableColors := getEnv(lookup, "TELEGRAM_DISABLE_COLORS", "")
+	timeFormat := getEnv(lookup, "TELEGRAM_TIME_FORMAT", "")
 
 	if botToken == "" {
 		return nil, fmt.Errorf("TELEGRAM_BOT_TOKEN environment variable not set")
@@ -79,7 +81,6 @@ func WithEnv() (*Logger, error) {
 		return nil, fmt.Errorf("TELEGRAM_CHAT_ID environment variable not set")
 	}
 
-	config := DefaultConfig()
 	config.BotToken = botToken
 	config.ChatID = chatID
 
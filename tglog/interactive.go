@@ -0,0 +1,476 @@
+package tglog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// InteractionMode selects how the logger receives inbound Telegram updates.
+type InteractionMode int
+
+const (
+	// LongPoll fetches updates via repeated calls to the getUpdates API.
+	LongPoll InteractionMode = iota
+	// Webhook runs an HTTP server that Telegram pushes updates to.
+	Webhook
+)
+
+// Args is the parsed representation of an inbound command, handed to
+// registered command handlers.
+type Args struct {
+	// Command is the command name without its leading slash, e.g. "tail".
+	Command string
+	// Fields holds the whitespace-separated arguments following the command.
+	Fields []string
+	// Raw is the full, unparsed text of the message.
+	Raw string
+	// ChatID is the chat the command was sent from.
+	ChatID string
+	// UserID is the Telegram user ID of the sender, if known.
+	UserID int64
+}
+
+// CommandHandler processes a single command invocation and returns the text
+// to reply with.
+type CommandHandler func(Args) string
+
+// CommandRouter dispatches incoming commands to registered handlers.
+type CommandRouter struct {
+	mu       sync.RWMutex
+	handlers map[string]CommandHandler
+}
+
+// NewCommandRouter creates an empty CommandRouter.
+func NewCommandRouter() *CommandRouter {
+	return &CommandRouter{handlers: make(map[string]CommandHandler)}
+}
+
+// RegisterCommand associates name (without a leading slash) with handler,
+// overwriting any previous registration for that name.
+func (r *CommandRouter) RegisterCommand(name string, handler CommandHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers[name] = handler
+}
+
+// Dispatch looks up and invokes the handler for args.Command, returning its
+// reply and whether a handler was found.
+func (r *CommandRouter) Dispatch(args Args) (string, bool) {
+	r.mu.RLock()
+	handler, ok := r.handlers[args.Command]
+	r.mu.RUnlock()
+	if !ok {
+		return "", false
+	}
+	return handler(args), true
+}
+
+// HasCommand reports whether name already has a registered handler.
+func (r *CommandRouter) HasCommand(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.handlers[name]
+	return ok
+}
+
+// AuthChecker decides whether a chat/user is allowed to issue commands.
+type AuthChecker interface {
+	Authorize(chatID string, userID int64) bool
+}
+
+// ChatWhitelist authorizes any chat ID present in the set.
+type ChatWhitelist map[string]bool
+
+// NewChatWhitelist builds a ChatWhitelist from a list of chat IDs.
+func NewChatWhitelist(chatIDs ...string) ChatWhitelist {
+	w := make(ChatWhitelist, len(chatIDs))
+	for _, id := range chatIDs {
+		w[id] = true
+	}
+	return w
+}
+
+// Authorize implements AuthChecker.
+func (w ChatWhitelist) Authorize(chatID string, _ int64) bool {
+	return w[chatID]
+}
+
+// UserWhitelist authorizes any Telegram user ID present in the set,
+// regardless of chat. Used to build an AuthChecker from Config.AllowedUserIDs.
+type UserWhitelist map[int64]bool
+
+// NewUserWhitelist builds a UserWhitelist from a list of user IDs.
+func NewUserWhitelist(userIDs ...int64) UserWhitelist {
+	w := make(UserWhitelist, len(userIDs))
+	for _, id := range userIDs {
+		w[id] = true
+	}
+	return w
+}
+
+// Authorize implements AuthChecker.
+func (w UserWhitelist) Authorize(_ string, userID int64) bool {
+	return w[userID]
+}
+
+// InteractionConfig configures the inbound command subsystem started by
+// Logger.StartInteractive.
+type InteractionConfig struct {
+	// Mode selects long-polling or webhook delivery (default LongPoll).
+	Mode InteractionMode
+	// PollInterval is sent as the getUpdates long-poll timeout in LongPoll
+	// mode, in whole seconds, and is also the wait before retrying after a
+	// failed call (default 2s).
+	PollInterval time.Duration
+	// WebhookAddr is the address the webhook HTTP server listens on, e.g.
+	// ":8443". Required in Webhook mode.
+	WebhookAddr string
+	// WebhookPath is the HTTP path Telegram will POST updates to (default "/").
+	WebhookPath string
+	// AuthChecker authorizes inbound commands; if nil, all chats are allowed.
+	AuthChecker AuthChecker
+	// RingBufferSize is the number of recent formatted log entries kept in
+	// memory for /tail and /filter (default 200).
+	RingBufferSize int
+	// Router is used to dispatch commands; if nil, a new CommandRouter with
+	// the built-in commands is created.
+	Router *CommandRouter
+}
+
+// telegramUpdate is the minimal subset of Telegram's Update object used here.
+type telegramUpdate struct {
+	UpdateID int64 `json:"update_id"`
+	Message  *struct {
+		Text string `json:"text"`
+		Chat struct {
+			ID int64 `json:"id"`
+		} `json:"chat"`
+		From struct {
+			ID int64 `json:"id"`
+		} `json:"from"`
+	} `json:"message"`
+}
+
+type getUpdatesResponse struct {
+	OK     bool             `json:"ok"`
+	Result []telegramUpdate `json:"result"`
+}
+
+// StartInteractive starts the inbound command subsystem and returns once it
+// has been set up; delivery of updates runs in a background goroutine until
+// ctx is cancelled. Calling it more than once on the same Logger is an error.
+func (l *Logger) StartInteractive(ctx context.Context, cfg InteractionConfig) error {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 2 * time.Second
+	}
+	if cfg.WebhookPath == "" {
+		cfg.WebhookPath = "/"
+	}
+	if cfg.RingBufferSize <= 0 {
+		cfg.RingBufferSize = 200
+	}
+	if cfg.Router == nil {
+		cfg.Router = NewCommandRouter()
+	}
+
+	l.interactiveMu.Lock()
+	if l.interactive != nil {
+		l.interactiveMu.Unlock()
+		return fmt.Errorf("tglog: interactive mode already started")
+	}
+	l.ringCap = cfg.RingBufferSize
+	l.router = cfg.Router
+	l.authChecker = cfg.AuthChecker
+	l.interactive = &cfg
+	l.interactiveMu.Unlock()
+
+	l.registerBuiltinCommands()
+
+	switch cfg.Mode {
+	case Webhook:
+		return l.startWebhook(ctx, cfg)
+	default:
+		go l.pollUpdates(ctx, cfg)
+		return nil
+	}
+}
+
+// CommandHandlers is a set of custom command handlers keyed by command name
+// (without the leading slash), passed to StartInteraction.
+type CommandHandlers map[string]CommandHandler
+
+// StartInteraction is a convenience wrapper around StartInteractive for the
+// common case: long-polling getUpdates with the built-in commands (/level,
+// /mute, /unmute, /status, /tail, /filter), handlers registered for any
+// custom commands, and Config.AllowedUserIDs enforced as an allowlist if
+// set. Use StartInteractive directly for webhook delivery or a custom
+// AuthChecker/CommandRouter.
+func (l *Logger) StartInteraction(ctx context.Context, handlers CommandHandlers) error {
+	router := NewCommandRouter()
+	for name, handler := range handlers {
+		router.RegisterCommand(name, handler)
+	}
+
+	cfg := InteractionConfig{Router: router}
+	if len(l.config.AllowedUserIDs) > 0 {
+		cfg.AuthChecker = NewUserWhitelist(l.config.AllowedUserIDs...)
+	}
+	return l.StartInteractive(ctx, cfg)
+}
+
+// RegisterCommand registers a custom command handler on the logger's
+// interactive router. StartInteractive must be called first.
+func (l *Logger) RegisterCommand(name string, handler CommandHandler) {
+	l.interactiveMu.RLock()
+	router := l.router
+	l.interactiveMu.RUnlock()
+	if router == nil {
+		return
+	}
+	router.RegisterCommand(name, handler)
+}
+
+// registerBuiltinCommands installs the library's default commands, skipping
+// any name a caller already registered (e.g. via StartInteraction's
+// CommandHandlers) so a custom handler always wins over the built-in.
+func (l *Logger) registerBuiltinCommands() {
+	register := func(name string, handler CommandHandler) {
+		if !l.router.HasCommand(name) {
+			l.router.RegisterCommand(name, handler)
+		}
+	}
+
+	register("tail", func(a Args) string {
+		n := 20
+		if len(a.Fields) > 0 {
+			if v, err := strconv.Atoi(a.Fields[0]); err == nil && v > 0 {
+				n = v
+			}
+		}
+		return strings.Join(l.tailEntries(n, ""), "\n")
+	})
+	register("filter", func(a Args) string {
+		if len(a.Fields) == 0 {
+			return "usage: /filter <substr>"
+		}
+		return strings.Join(l.tailEntries(l.ringCap, strings.Join(a.Fields, " ")), "\n")
+	})
+	register("level", func(a Args) string {
+		if len(a.Fields) == 0 {
+			return fmt.Sprintf("current level: %s", l.currentLevelName())
+		}
+		lvl := GetLogLevelFromString(a.Fields[0])
+		l.levelMu.Lock()
+		l.config.MinLevel = lvl
+		l.levelMu.Unlock()
+		return fmt.Sprintf("level set to %s", levelName(lvl))
+	})
+	register("stats", func(a Args) string {
+		return fmt.Sprintf("buffered entries: %d/%d", l.bufferedCount(), l.ringCap)
+	})
+	register("mute", func(a Args) string {
+		if len(a.Fields) == 0 {
+			return "usage: /mute <duration, e.g. 10m>"
+		}
+		d, err := time.ParseDuration(a.Fields[0])
+		if err != nil {
+			return fmt.Sprintf("invalid duration: %v", err)
+		}
+		atomic.StoreInt64(&l.muteUntil, time.Now().Add(d).UnixNano())
+		return fmt.Sprintf("muted for %s", d)
+	})
+	register("unmute", func(a Args) string {
+		atomic.StoreInt64(&l.muteUntil, 0)
+		return "unmuted"
+	})
+	register("status", func(a Args) string {
+		return fmt.Sprintf("level: %s\nmuted: %s\nbuffered entries: %d/%d",
+			l.currentLevelName(), l.muteStatus(), l.bufferedCount(), l.ringCap)
+	})
+}
+
+// currentLevelName returns the logger's current MinLevel as a string, used
+// by both the "level" and "status" commands.
+func (l *Logger) currentLevelName() string {
+	l.levelMu.RLock()
+	defer l.levelMu.RUnlock()
+	return levelName(l.config.MinLevel)
+}
+
+// bufferedCount returns how many entries are currently held in the ring
+// buffer, used by both the "stats" and "status" commands.
+func (l *Logger) bufferedCount() int {
+	l.interactiveMu.RLock()
+	defer l.interactiveMu.RUnlock()
+	return len(l.ring)
+}
+
+// muteStatus returns "no" or "until <formatted time>", used by the "status"
+// command.
+func (l *Logger) muteStatus() string {
+	until := atomic.LoadInt64(&l.muteUntil)
+	if until == 0 || time.Now().UnixNano() >= until {
+		return "no"
+	}
+	return fmt.Sprintf("until %s", time.Unix(0, until).Format(l.config.TimeFormat))
+}
+
+func levelName(l LogLevel) string {
+	switch l {
+	case Debug:
+		return "debug"
+	case Info:
+		return "info"
+	case Warning:
+		return "warning"
+	case Error:
+		return "error"
+	case Fatal:
+		return "fatal"
+	default:
+		return "unknown"
+	}
+}
+
+// isMuted reports whether outbound sends are currently suppressed by /mute.
+func (l *Logger) isMuted() bool {
+	until := atomic.LoadInt64(&l.muteUntil)
+	return until != 0 && time.Now().UnixNano() < until
+}
+
+// pushRing appends a formatted entry to the in-memory ring buffer, if one is
+// configured.
+func (l *Logger) pushRing(entry string) {
+	l.interactiveMu.Lock()
+	defer l.interactiveMu.Unlock()
+	if l.ringCap == 0 {
+		return
+	}
+	l.ring = append(l.ring, entry)
+	if len(l.ring) > l.ringCap {
+		l.ring = l.ring[len(l.ring)-l.ringCap:]
+	}
+}
+
+func (l *Logger) tailEntries(n int, substr string) []string {
+	l.interactiveMu.RLock()
+	defer l.interactiveMu.RUnlock()
+	var matched []string
+	for _, e := range l.ring {
+		if substr == "" || strings.Contains(e, substr) {
+			matched = append(matched, e)
+		}
+	}
+	if n > 0 && len(matched) > n {
+		matched = matched[len(matched)-n:]
+	}
+	return matched
+}
+
+func (l *Logger) pollUpdates(ctx context.Context, cfg InteractionConfig) {
+	var offset int64
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		url := fmt.Sprintf("https://api.telegram.org/bot%s/getUpdates?timeout=%d&offset=%d",
+			l.sender.currentBotToken(), int(cfg.PollInterval.Seconds()), offset)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			time.Sleep(cfg.PollInterval)
+			continue
+		}
+		resp, err := l.httpClient.Do(req)
+		if err != nil {
+			time.Sleep(cfg.PollInterval)
+			continue
+		}
+		var parsed getUpdatesResponse
+		err = json.NewDecoder(resp.Body).Decode(&parsed)
+		resp.Body.Close()
+		if err != nil || !parsed.OK {
+			time.Sleep(cfg.PollInterval)
+			continue
+		}
+
+		for _, upd := range parsed.Result {
+			offset = upd.UpdateID + 1
+			l.handleUpdate(upd)
+		}
+	}
+}
+
+func (l *Logger) startWebhook(ctx context.Context, cfg InteractionConfig) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc(cfg.WebhookPath, func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		var upd telegramUpdate
+		if err := json.Unmarshal(body, &upd); err != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		l.handleUpdate(upd)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	server := &http.Server{Addr: cfg.WebhookAddr, Handler: mux}
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+	go server.ListenAndServe()
+	return nil
+}
+
+func (l *Logger) handleUpdate(upd telegramUpdate) {
+	if upd.Message == nil || !strings.HasPrefix(upd.Message.Text, "/") {
+		return
+	}
+	chatID := strconv.FormatInt(upd.Message.Chat.ID, 10)
+	userID := upd.Message.From.ID
+
+	l.interactiveMu.RLock()
+	checker := l.authChecker
+	l.interactiveMu.RUnlock()
+	if checker != nil && !checker.Authorize(chatID, userID) {
+		return
+	}
+
+	fields := strings.Fields(upd.Message.Text)
+	cmd := strings.TrimPrefix(fields[0], "/")
+	args := Args{
+		Command: cmd,
+		Fields:  fields[1:],
+		Raw:     upd.Message.Text,
+		ChatID:  chatID,
+		UserID:  userID,
+	}
+
+	l.interactiveMu.RLock()
+	router := l.router
+	l.interactiveMu.RUnlock()
+	if router == nil {
+		return
+	}
+
+	reply, ok := router.Dispatch(args)
+	if !ok || reply == "" {
+		return
+	}
+	l.sendReply(args.ChatID, reply, l.config.Formatter.ParseMode())
+}
@@ -0,0 +1,373 @@
+package tglog
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCommandRouterDispatch(t *testing.T) {
+	router := NewCommandRouter()
+	router.RegisterCommand("ping", func(a Args) string {
+		return "pong"
+	})
+
+	reply, ok := router.Dispatch(Args{Command: "ping"})
+	if !ok {
+		t.Fatalf("expected ping command to be found")
+	}
+	if reply != "pong" {
+		t.Errorf("expected reply %q, got %q", "pong", reply)
+	}
+
+	if _, ok := router.Dispatch(Args{Command: "unknown"}); ok {
+		t.Errorf("expected unknown command to be unregistered")
+	}
+}
+
+func TestChatWhitelistAuthorize(t *testing.T) {
+	w := NewChatWhitelist("123", "456")
+
+	if !w.Authorize("123", 0) {
+		t.Errorf("expected chat 123 to be authorized")
+	}
+	if w.Authorize("789", 0) {
+		t.Errorf("expected chat 789 to be rejected")
+	}
+}
+
+func TestUserWhitelistAuthorize(t *testing.T) {
+	w := NewUserWhitelist(111, 222)
+
+	if !w.Authorize("any chat", 111) {
+		t.Errorf("expected user 111 to be authorized")
+	}
+	if w.Authorize("any chat", 333) {
+		t.Errorf("expected user 333 to be rejected")
+	}
+}
+
+func TestMuteUnmuteBuiltinCommands(t *testing.T) {
+	config := DefaultConfig()
+	config.BotToken = "test_token"
+	config.ChatID = "test_chat_id"
+	config.Async = false
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	logger.router = NewCommandRouter()
+	logger.registerBuiltinCommands()
+
+	if _, ok := logger.router.Dispatch(Args{Command: "mute", Fields: []string{"10m"}}); !ok {
+		t.Fatalf("expected mute command to be registered")
+	}
+	if !logger.isMuted() {
+		t.Errorf("expected logger to be muted after /mute")
+	}
+
+	if _, ok := logger.router.Dispatch(Args{Command: "unmute"}); !ok {
+		t.Fatalf("expected unmute command to be registered")
+	}
+	if logger.isMuted() {
+		t.Errorf("expected logger to be unmuted after /unmute")
+	}
+}
+
+func TestStatusBuiltinCommandReportsLevelAndBuffer(t *testing.T) {
+	config := DefaultConfig()
+	config.BotToken = "test_token"
+	config.ChatID = "test_chat_id"
+	config.Async = false
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	logger.ringCap = 5
+	logger.router = NewCommandRouter()
+	logger.registerBuiltinCommands()
+
+	reply, ok := logger.router.Dispatch(Args{Command: "status"})
+	if !ok {
+		t.Fatalf("expected status command to be registered")
+	}
+	if !strings.Contains(reply, "level: info") || !strings.Contains(reply, "muted: no") {
+		t.Errorf("expected status to report current level and mute state, got %q", reply)
+	}
+}
+
+func TestStartInteractionRegistersHandlersAndAllowlist(t *testing.T) {
+	config := DefaultConfig()
+	config.BotToken = "test_token"
+	config.ChatID = "test_chat_id"
+	config.Async = false
+	config.AllowedUserIDs = []int64{42}
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err = logger.StartInteraction(ctx, CommandHandlers{
+		"ping": func(a Args) string { return "pong" },
+	})
+	if err != nil {
+		t.Fatalf("StartInteraction failed: %v", err)
+	}
+
+	reply, ok := logger.router.Dispatch(Args{Command: "ping"})
+	if !ok || reply != "pong" {
+		t.Errorf("expected custom ping handler to be registered, got %q, %v", reply, ok)
+	}
+	if _, ok := logger.router.Dispatch(Args{Command: "status"}); !ok {
+		t.Errorf("expected built-in status command to also be registered")
+	}
+
+	if logger.authChecker == nil {
+		t.Fatal("expected AllowedUserIDs to produce an AuthChecker")
+	}
+	if !logger.authChecker.Authorize("any chat", 42) {
+		t.Errorf("expected allowlisted user 42 to be authorized")
+	}
+	if logger.authChecker.Authorize("any chat", 99) {
+		t.Errorf("expected non-allowlisted user 99 to be rejected")
+	}
+}
+
+func TestStartInteractionCustomHandlerOverridesBuiltin(t *testing.T) {
+	config := DefaultConfig()
+	config.BotToken = "test_token"
+	config.ChatID = "test_chat_id"
+	config.Async = false
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err = logger.StartInteraction(ctx, CommandHandlers{
+		"mute": func(a Args) string { return "custom mute" },
+	})
+	if err != nil {
+		t.Fatalf("StartInteraction failed: %v", err)
+	}
+
+	reply, ok := logger.router.Dispatch(Args{Command: "mute", Fields: []string{"10m"}})
+	if !ok || reply != "custom mute" {
+		t.Errorf("expected custom mute handler to win over the built-in, got %q, %v", reply, ok)
+	}
+	if logger.isMuted() {
+		t.Errorf("expected the built-in mute logic not to run when overridden")
+	}
+}
+
+func TestPollUpdatesUsesRotatedBotToken(t *testing.T) {
+	var mu sync.Mutex
+	var pathsSeen []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		pathsSeen = append(pathsSeen, r.URL.Path)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok":true,"result":[]}`))
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.BotToken = "token_v1"
+	config.ChatID = "test_chat_id"
+	config.Async = false
+	config.HTTPClient = &http.Client{Transport: &testTransport{originalTransport: http.DefaultTransport, testServer: server, t: t}}
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err = logger.StartInteractive(ctx, InteractionConfig{PollInterval: 20 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("StartInteractive failed: %v", err)
+	}
+
+	sawPath := func(substr string) bool {
+		deadline := time.Now().Add(2 * time.Second)
+		for time.Now().Before(deadline) {
+			mu.Lock()
+			for _, p := range pathsSeen {
+				if strings.Contains(p, substr) {
+					mu.Unlock()
+					return true
+				}
+			}
+			mu.Unlock()
+			time.Sleep(10 * time.Millisecond)
+		}
+		return false
+	}
+
+	if !sawPath("token_v1") {
+		t.Fatal("expected at least one poll with the original bot token")
+	}
+
+	logger.sender.setCredentials("token_v2", "test_chat_id")
+
+	if !sawPath("token_v2") {
+		t.Fatal("expected pollUpdates to pick up the rotated bot token")
+	}
+}
+
+func TestInteractiveCommandsStayResponsiveDuringQueueBackpressure(t *testing.T) {
+	block := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-block // hang every request, simulating Telegram being unreachable
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.BotToken = "test_token"
+	config.ChatID = "test_chat_id"
+	config.Async = true
+	config.HTTPClient = &http.Client{Transport: &testTransport{originalTransport: http.DefaultTransport, testServer: server, t: t}}
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	floodDone := make(chan struct{})
+	defer func() {
+		close(block) // let the hung server handler return
+		<-floodDone  // wait for the flooding goroutine before closing the logger
+		logger.Close()
+	}()
+
+	logger.router = NewCommandRouter()
+	logger.registerBuiltinCommands()
+
+	// Flood the async queue past its buffer, in the background, so some
+	// goroutine is left blocked on msgQueue <- message{...} while holding
+	// l.mu for the rest of the test.
+	go func() {
+		defer close(floodDone)
+		for i := 0; i < 200; i++ {
+			logger.Info("flooding message")
+		}
+	}()
+	time.Sleep(200 * time.Millisecond) // let the queue fill and backpressure kick in
+
+	done := make(chan struct{})
+	go func() {
+		logger.router.Dispatch(Args{Command: "status"})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("interactive command dispatch hung during queue backpressure")
+	}
+}
+
+func TestHandleUpdateRepliesToRequestingChatNotConfigChatID(t *testing.T) {
+	var mu sync.Mutex
+	var chatIDsSent []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg telegramMessage
+		json.NewDecoder(r.Body).Decode(&msg)
+		mu.Lock()
+		chatIDsSent = append(chatIDsSent, msg.ChatID)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.BotToken = "test_token"
+	config.ChatID = "primary_chat"
+	config.Async = false
+	config.HTTPClient = &http.Client{Transport: &testTransport{originalTransport: http.DefaultTransport, testServer: server, t: t}}
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	logger.authChecker = NewChatWhitelist("primary_chat", "999")
+	logger.router = NewCommandRouter()
+	logger.registerBuiltinCommands()
+
+	logger.handleUpdate(telegramUpdate{
+		UpdateID: 1,
+		Message: &struct {
+			Text string `json:"text"`
+			Chat struct {
+				ID int64 `json:"id"`
+			} `json:"chat"`
+			From struct {
+				ID int64 `json:"id"`
+			} `json:"from"`
+		}{Text: "/status", Chat: struct {
+			ID int64 `json:"id"`
+		}{ID: 999}},
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(chatIDsSent) != 1 {
+		t.Fatalf("expected exactly one reply to be sent, got %d: %v", len(chatIDsSent), chatIDsSent)
+	}
+	if chatIDsSent[0] != "999" {
+		t.Errorf("expected /status reply to be delivered to the requesting chat 999, got %q (Config.ChatID is %q)", chatIDsSent[0], config.ChatID)
+	}
+}
+
+func TestLoggerRingBufferTail(t *testing.T) {
+	config := DefaultConfig()
+	config.BotToken = "test_token"
+	config.ChatID = "test_chat_id"
+	config.Async = false
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	logger.ringCap = 3
+
+	logger.pushRing("one")
+	logger.pushRing("two")
+	logger.pushRing("three")
+	logger.pushRing("four")
+
+	entries := logger.tailEntries(10, "")
+	if len(entries) != 3 {
+		t.Fatalf("expected ring buffer to cap at 3 entries, got %d", len(entries))
+	}
+	if entries[0] != "two" || entries[2] != "four" {
+		t.Errorf("expected oldest entry to be evicted, got %v", entries)
+	}
+
+	filtered := logger.tailEntries(10, "three")
+	if len(filtered) != 1 || filtered[0] != "three" {
+		t.Errorf("expected filter to return only matching entries, got %v", filtered)
+	}
+}
@@ -0,0 +1,298 @@
+// Package listener provides Unix-socket ingestion for tglog, so non-Go
+// processes (shell scripts, Python workers, etc.) can forward log lines to a
+// single central tglog.Logger without linking against this module.
+package listener
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nshiffer/tglog"
+)
+
+// maxLineSize caps a single plain-text/JSON record so one oversized line
+// (e.g. a large stack trace) can't exceed bufio.Scanner's default ~64KB
+// limit and silently kill ingestion for the rest of the connection.
+const maxLineSize = 1 << 20 // 1MB
+
+// ListenOptions configures a Listener. Use DefaultListenOptions to get
+// sensible defaults rather than the zero value, whose DefaultLevel of
+// LogLevel(0) is tglog.Debug.
+type ListenOptions struct {
+	// Perm is the Unix socket file's permissions. Zero means 0600.
+	Perm os.FileMode
+	// DefaultLevel is used for plain-text lines and JSON records that don't
+	// specify a level.
+	DefaultLevel tglog.LogLevel
+}
+
+// DefaultListenOptions returns ListenOptions with DefaultLevel set to
+// tglog.Info, matching tglog.DefaultConfig's MinLevel.
+func DefaultListenOptions() ListenOptions {
+	return ListenOptions{DefaultLevel: tglog.Info}
+}
+
+// jsonRecord is the wire format accepted both for JSON lines and for HTTP
+// POSTs to /log: {"level":"error","msg":"...","fields":{"k":"v"}}.
+type jsonRecord struct {
+	Level  string                 `json:"level"`
+	Msg    string                 `json:"msg"`
+	Fields map[string]interface{} `json:"fields"`
+}
+
+// Listener accepts log records over a Unix domain socket and forwards them
+// to an underlying tglog.Logger.
+type Listener struct {
+	logger *tglog.Logger
+	ln     net.Listener
+	opts   ListenOptions
+
+	wg sync.WaitGroup
+
+	connsMu sync.Mutex
+	conns   map[net.Conn]struct{}
+	closed  bool
+}
+
+// ListenUnix opens a Unix domain socket at path and starts accepting
+// connections in the background, translating incoming records into calls on
+// logger. Each connection may send newline-delimited plain text, one JSON
+// record per line, or a single HTTP POST to /log.
+func ListenUnix(path string, logger *tglog.Logger, opts ListenOptions) (*Listener, error) {
+	if opts.Perm == 0 {
+		opts.Perm = 0o600
+	}
+
+	// Remove a stale socket file from a previous run so Listen doesn't fail
+	// with "address already in use".
+	os.Remove(path)
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("tglog/listener: failed to listen on %s: %w", path, err)
+	}
+	if err := os.Chmod(path, opts.Perm); err != nil {
+		ln.Close()
+		return nil, fmt.Errorf("tglog/listener: failed to set socket permissions: %w", err)
+	}
+
+	l := &Listener{logger: logger, ln: ln, opts: opts, conns: make(map[net.Conn]struct{})}
+	l.wg.Add(1)
+	go l.serve()
+	return l, nil
+}
+
+// Addr returns the listener's socket address.
+func (l *Listener) Addr() net.Addr {
+	return l.ln.Addr()
+}
+
+// Close stops accepting new connections, closes any still-open connections
+// so their handlers unblock, and waits for in-flight connections to finish.
+// It does not close the underlying Logger.
+func (l *Listener) Close() error {
+	err := l.ln.Close()
+
+	l.connsMu.Lock()
+	l.closed = true
+	for conn := range l.conns {
+		conn.Close()
+	}
+	l.connsMu.Unlock()
+
+	l.wg.Wait()
+	return err
+}
+
+func (l *Listener) serve() {
+	defer l.wg.Done()
+	var retryDelay time.Duration
+	for {
+		conn, err := l.ln.Accept()
+		if err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Temporary() {
+				if retryDelay == 0 {
+					retryDelay = 5 * time.Millisecond
+				} else {
+					retryDelay *= 2
+				}
+				if max := time.Second; retryDelay > max {
+					retryDelay = max
+				}
+				fmt.Fprintf(os.Stderr, "tglog/listener: accept error, retrying in %v: %v\n", retryDelay, err)
+				time.Sleep(retryDelay)
+				continue
+			}
+			return // listener closed, or a non-temporary error
+		}
+		retryDelay = 0
+
+		if !l.trackConn(conn) {
+			conn.Close() // Close already ran; reject new connections
+			continue
+		}
+		l.wg.Add(1)
+		go l.handleConn(conn)
+	}
+}
+
+// trackConn registers conn so Close can force it closed, unless Close has
+// already run.
+func (l *Listener) trackConn(conn net.Conn) bool {
+	l.connsMu.Lock()
+	defer l.connsMu.Unlock()
+	if l.closed {
+		return false
+	}
+	l.conns[conn] = struct{}{}
+	return true
+}
+
+func (l *Listener) untrackConn(conn net.Conn) {
+	l.connsMu.Lock()
+	delete(l.conns, conn)
+	l.connsMu.Unlock()
+}
+
+func (l *Listener) handleConn(conn net.Conn) {
+	defer l.wg.Done()
+	defer l.untrackConn(conn)
+	defer conn.Close()
+
+	br := bufio.NewReader(conn)
+	peek, err := br.Peek(4)
+	if err == nil && looksLikeHTTPRequest(peek) {
+		l.serveHTTP(&bufConn{Conn: conn, r: br})
+		return
+	}
+
+	scanner := bufio.NewScanner(br)
+	scanner.Buffer(make([]byte, 0, 4096), maxLineSize)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		l.ingestLine(line)
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintf(os.Stderr, "tglog/listener: connection read error: %v\n", err)
+	}
+}
+
+// looksLikeHTTPRequest reports whether peek starts with an HTTP request
+// method, the cue used to distinguish an HTTP POST to /log from a
+// newline-delimited plain-text or JSON stream on the same socket.
+func looksLikeHTTPRequest(peek []byte) bool {
+	s := string(peek)
+	return strings.HasPrefix(s, "POST") || strings.HasPrefix(s, "GET ") || strings.HasPrefix(s, "PUT ")
+}
+
+// ingestLine forwards one newline-delimited record: a JSON object if it
+// parses as one, otherwise the raw line as plain text at DefaultLevel.
+func (l *Listener) ingestLine(line string) {
+	if strings.HasPrefix(line, "{") {
+		if err := l.ingestJSON([]byte(line)); err == nil {
+			return
+		}
+		// Not valid JSON after all; log it verbatim as plain text below.
+	}
+	l.logger.LogWith(l.opts.DefaultLevel, nil, "%s", line)
+}
+
+func (l *Listener) ingestJSON(data []byte) error {
+	var rec jsonRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return fmt.Errorf("tglog/listener: invalid JSON record: %w", err)
+	}
+
+	level := l.opts.DefaultLevel
+	if rec.Level != "" {
+		level = tglog.GetLogLevelFromString(rec.Level)
+	}
+	l.logger.LogWith(level, tglog.Fields(rec.Fields), "%s", rec.Msg)
+	return nil
+}
+
+// serveHTTP runs an http.Server scoped to this single already-accepted
+// connection, so it can handle a POST /log using net/http without giving up
+// the socket's ability to also accept plain-text/JSON connections.
+func (l *Listener) serveHTTP(conn net.Conn) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/log", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "failed to read body", http.StatusBadRequest)
+			return
+		}
+		if err := l.ingestJSON(body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	oneConn := newOneConnListener(conn)
+	srv := &http.Server{
+		Handler: mux,
+		ConnState: func(_ net.Conn, state http.ConnState) {
+			if state == http.StateClosed || state == http.StateHijacked {
+				oneConn.Close()
+			}
+		},
+	}
+	srv.Serve(oneConn)
+}
+
+// bufConn adapts a net.Conn whose initial bytes were already consumed into a
+// bufio.Reader (to peek at them) back into something that reads through that
+// buffer first.
+type bufConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
+
+// oneConnListener is a net.Listener that yields a single already-accepted
+// connection and then blocks until Close, letting http.Server.Serve drive an
+// individual connection without owning the real socket listener.
+type oneConnListener struct {
+	conn net.Conn
+	addr net.Addr
+	done chan struct{}
+	once sync.Once
+}
+
+func newOneConnListener(conn net.Conn) *oneConnListener {
+	return &oneConnListener{conn: conn, addr: conn.LocalAddr(), done: make(chan struct{})}
+}
+
+func (o *oneConnListener) Accept() (net.Conn, error) {
+	if o.conn != nil {
+		c := o.conn
+		o.conn = nil
+		return c, nil
+	}
+	<-o.done
+	return nil, io.EOF
+}
+
+func (o *oneConnListener) Close() error {
+	o.once.Do(func() { close(o.done) })
+	return nil
+}
+
+func (o *oneConnListener) Addr() net.Addr {
+	return o.addr
+}
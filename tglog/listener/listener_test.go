@@ -0,0 +1,232 @@
+package listener
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nshiffer/tglog"
+)
+
+// recordingSink captures every Entry sent through it, used to observe what
+// the Listener forwarded to a Logger without touching the network.
+type recordingSink struct {
+	mu      sync.Mutex
+	entries []tglog.Entry
+}
+
+func (s *recordingSink) Send(_ context.Context, e tglog.Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, e)
+	return nil
+}
+
+func (s *recordingSink) Close() error { return nil }
+
+func (s *recordingSink) snapshot() []tglog.Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]tglog.Entry, len(s.entries))
+	copy(out, s.entries)
+	return out
+}
+
+func newTestLogger(t *testing.T, sink *recordingSink) *tglog.Logger {
+	t.Helper()
+	config := tglog.DefaultConfig()
+	config.BotToken = "test_token"
+	config.ChatID = "test_chat_id"
+	config.Async = false
+	config.MinLevel = tglog.Debug
+	config.Sinks = []tglog.SinkSpec{{Sink: sink, MinLevel: tglog.Debug}}
+	// No real HTTP calls: the primary Telegram client's transport fails
+	// every request with an error. In sync mode the sink receives each
+	// entry before that send is attempted.
+	config.HTTPClient = &http.Client{Transport: failingTransport{}}
+
+	logger, err := tglog.New(config)
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	t.Cleanup(logger.Close)
+	return logger
+}
+
+type failingTransport struct{}
+
+func (failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, fmt.Errorf("no network in this test")
+}
+
+func waitForEntries(t *testing.T, sink *recordingSink, n int) []tglog.Entry {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if entries := sink.snapshot(); len(entries) >= n {
+			return entries
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %d entries, got %d", n, len(sink.snapshot()))
+	return nil
+}
+
+func TestListenUnixForwardsPlainTextLine(t *testing.T) {
+	sink := &recordingSink{}
+	logger := newTestLogger(t, sink)
+
+	socketPath := filepath.Join(t.TempDir(), "tglog.sock")
+	ln, err := ListenUnix(socketPath, logger, DefaultListenOptions())
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer ln.Close()
+
+	conn, err := net.Dial("unix", socketPath)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	fmt.Fprintln(conn, "disk usage at 95%")
+	conn.Close()
+
+	entries := waitForEntries(t, sink, 1)
+	if entries[0].Level != tglog.Info {
+		t.Errorf("expected plain text to use DefaultLevel (Info), got %v", entries[0].Level)
+	}
+	if !strings.Contains(entries[0].Message, "disk usage at 95%") {
+		t.Errorf("expected forwarded message to contain the line, got %q", entries[0].Message)
+	}
+}
+
+func TestListenUnixForwardsJSONLine(t *testing.T) {
+	sink := &recordingSink{}
+	logger := newTestLogger(t, sink)
+
+	socketPath := filepath.Join(t.TempDir(), "tglog.sock")
+	ln, err := ListenUnix(socketPath, logger, DefaultListenOptions())
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer ln.Close()
+
+	conn, err := net.Dial("unix", socketPath)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	fmt.Fprintln(conn, `{"level":"error","msg":"worker crashed","fields":{"worker_id":"3"}}`)
+	conn.Close()
+
+	entries := waitForEntries(t, sink, 1)
+	if entries[0].Level != tglog.Error {
+		t.Errorf("expected level from JSON record, got %v", entries[0].Level)
+	}
+	if !strings.Contains(entries[0].Message, "worker crashed") {
+		t.Errorf("expected forwarded message, got %q", entries[0].Message)
+	}
+	if entries[0].Fields["worker_id"] != "3" {
+		t.Errorf("expected fields forwarded from JSON record, got %v", entries[0].Fields)
+	}
+}
+
+func TestListenUnixForwardsHTTPPostToLog(t *testing.T) {
+	sink := &recordingSink{}
+	logger := newTestLogger(t, sink)
+
+	socketPath := filepath.Join(t.TempDir(), "tglog.sock")
+	ln, err := ListenUnix(socketPath, logger, DefaultListenOptions())
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer ln.Close()
+
+	conn, err := net.Dial("unix", socketPath)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer conn.Close()
+
+	req, err := http.NewRequest(http.MethodPost, "http://unix/log", strings.NewReader(`{"level":"warning","msg":"queue backing up"}`))
+	if err != nil {
+		t.Fatalf("failed to build request: %v", err)
+	}
+	if err := req.Write(conn); err != nil {
+		t.Fatalf("failed to write request: %v", err)
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		t.Fatalf("failed to read response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d", resp.StatusCode)
+	}
+
+	entries := waitForEntries(t, sink, 1)
+	if entries[0].Level != tglog.Warning {
+		t.Errorf("expected level from HTTP record, got %v", entries[0].Level)
+	}
+	if !strings.Contains(entries[0].Message, "queue backing up") {
+		t.Errorf("expected forwarded message, got %q", entries[0].Message)
+	}
+}
+
+func TestListenUnixDefaultsSocketPermissionsTo0600(t *testing.T) {
+	sink := &recordingSink{}
+	logger := newTestLogger(t, sink)
+
+	socketPath := filepath.Join(t.TempDir(), "tglog.sock")
+	ln, err := ListenUnix(socketPath, logger, ListenOptions{})
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer ln.Close()
+
+	info, err := os.Stat(socketPath)
+	if err != nil {
+		t.Fatalf("failed to stat socket: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("expected default socket permissions 0600, got %o", perm)
+	}
+}
+
+// TestListenerCloseDoesNotHangWithIdleConnection guards against Close
+// blocking forever on a connection that never sends a newline and never
+// closes, which would leave a daemon's graceful shutdown stuck forever.
+func TestListenerCloseDoesNotHangWithIdleConnection(t *testing.T) {
+	sink := &recordingSink{}
+	logger := newTestLogger(t, sink)
+
+	socketPath := filepath.Join(t.TempDir(), "tglog.sock")
+	ln, err := ListenUnix(socketPath, logger, DefaultListenOptions())
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	conn, err := net.Dial("unix", socketPath)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer conn.Close()
+
+	closed := make(chan error, 1)
+	go func() { closed <- ln.Close() }()
+
+	select {
+	case err := <-closed:
+		if err != nil {
+			t.Errorf("Close returned error: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Close did not return while a connection was left idle/open")
+	}
+}
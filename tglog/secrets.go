@@ -0,0 +1,123 @@
+package tglog
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// defaultSecretRefreshInterval is used when Config.SecretRefreshInterval is
+// unset.
+const defaultSecretRefreshInterval = 5 * time.Minute
+
+// SecretProvider resolves the bot token and chat ID from an external source,
+// such as a secrets manager or vault, rather than a static Config field or a
+// mounted file. When Config.SecretProvider is set, New resolves credentials
+// from it at construction time and periodically refreshes them afterward so
+// a rotated token is picked up without restarting the process.
+type SecretProvider interface {
+	GetBotToken(ctx context.Context) (string, error)
+	GetChatID(ctx context.Context) (string, error)
+}
+
+// resolveSecrets fills in config.BotToken/ChatID from SecretProvider (if
+// set), else BotTokenFile/ChatIDFile (if set), leaving the literal fields
+// untouched otherwise. Called once, by New.
+func resolveSecrets(config *Config) error {
+	if config.SecretProvider != nil {
+		ctx := context.Background()
+		botToken, err := config.SecretProvider.GetBotToken(ctx)
+		if err != nil {
+			return fmt.Errorf("tglog: failed to resolve bot token from SecretProvider: %w", err)
+		}
+		chatID, err := config.SecretProvider.GetChatID(ctx)
+		if err != nil {
+			return fmt.Errorf("tglog: failed to resolve chat ID from SecretProvider: %w", err)
+		}
+		config.BotToken = botToken
+		config.ChatID = chatID
+		return nil
+	}
+
+	if config.BotTokenFile != "" {
+		botToken, err := readSecretFile(config.BotTokenFile)
+		if err != nil {
+			return fmt.Errorf("tglog: failed to read BotTokenFile: %w", err)
+		}
+		config.BotToken = botToken
+	}
+	if config.ChatIDFile != "" {
+		chatID, err := readSecretFile(config.ChatIDFile)
+		if err != nil {
+			return fmt.Errorf("tglog: failed to read ChatIDFile: %w", err)
+		}
+		config.ChatID = chatID
+	}
+	return nil
+}
+
+// readSecretFile reads path and trims surrounding whitespace, the same
+// convention Kubernetes/Docker secret mounts expect a consumer to apply.
+func readSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// startSecretRefresh launches the goroutine that periodically re-resolves
+// credentials from config.SecretProvider, stopped by Close via
+// secretRefreshStop. Only called from New when SecretProvider is set.
+func (l *Logger) startSecretRefresh() {
+	interval := l.config.SecretRefreshInterval
+	if interval <= 0 {
+		interval = defaultSecretRefreshInterval
+	}
+
+	l.secretRefreshStop = make(chan struct{})
+	l.secretRefreshWG.Add(1)
+	go func() {
+		defer l.secretRefreshWG.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				l.refreshSecrets()
+			case <-l.secretRefreshStop:
+				return
+			}
+		}
+	}()
+}
+
+// refreshSecrets re-resolves credentials from l.config.SecretProvider and, on
+// success, swaps them into the sender atomically. A refresh failure, or an
+// empty token/chat ID that would otherwise silently break delivery, is
+// logged to stderr (never the secret itself) and the previous credentials
+// stay in effect. The refresh passes a ctx with a 10-second timeout, the
+// same bound DefaultConfig uses for the Telegram HTTPClient; a
+// SecretProvider that ignores ctx cancellation is not interrupted by it.
+func (l *Logger) refreshSecrets() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	botToken, err := l.config.SecretProvider.GetBotToken(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tglog: failed to refresh bot token: %v\n", err)
+		return
+	}
+	chatID, err := l.config.SecretProvider.GetChatID(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tglog: failed to refresh chat ID: %v\n", err)
+		return
+	}
+	if botToken == "" || chatID == "" {
+		fmt.Fprintf(os.Stderr, "tglog: SecretProvider returned an empty bot token or chat ID during refresh, keeping previous credentials\n")
+		return
+	}
+	l.sender.setCredentials(botToken, chatID)
+}
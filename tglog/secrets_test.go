@@ -0,0 +1,206 @@
+package tglog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNewResolvesBotTokenAndChatIDFromFiles(t *testing.T) {
+	dir := t.TempDir()
+	tokenPath := filepath.Join(dir, "bot_token")
+	chatPath := filepath.Join(dir, "chat_id")
+	if err := os.WriteFile(tokenPath, []byte("file_token\n"), 0o600); err != nil {
+		t.Fatalf("failed to write token file: %v", err)
+	}
+	if err := os.WriteFile(chatPath, []byte("file_chat_id\n"), 0o600); err != nil {
+		t.Fatalf("failed to write chat ID file: %v", err)
+	}
+
+	config := DefaultConfig()
+	config.BotTokenFile = tokenPath
+	config.ChatIDFile = chatPath
+	config.Async = false
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	if logger.config.BotToken != "file_token" {
+		t.Errorf("expected BotToken resolved from BotTokenFile (trimmed), got %q", logger.config.BotToken)
+	}
+	if logger.config.ChatID != "file_chat_id" {
+		t.Errorf("expected ChatID resolved from ChatIDFile (trimmed), got %q", logger.config.ChatID)
+	}
+}
+
+// fakeSecretProvider returns credentials that can be swapped at any time via
+// set, simulating a rotating secret store.
+type fakeSecretProvider struct {
+	botToken atomic.Value // string
+	chatID   atomic.Value // string
+}
+
+func newFakeSecretProvider(botToken, chatID string) *fakeSecretProvider {
+	p := &fakeSecretProvider{}
+	p.set(botToken, chatID)
+	return p
+}
+
+func (p *fakeSecretProvider) set(botToken, chatID string) {
+	p.botToken.Store(botToken)
+	p.chatID.Store(chatID)
+}
+
+func (p *fakeSecretProvider) GetBotToken(ctx context.Context) (string, error) {
+	return p.botToken.Load().(string), nil
+}
+
+func (p *fakeSecretProvider) GetChatID(ctx context.Context) (string, error) {
+	return p.chatID.Load().(string), nil
+}
+
+func TestNewResolvesCredentialsFromSecretProvider(t *testing.T) {
+	config := DefaultConfig()
+	config.SecretProvider = newFakeSecretProvider("provider_token", "provider_chat_id")
+	config.Async = false
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	if logger.config.BotToken != "provider_token" {
+		t.Errorf("expected BotToken resolved from SecretProvider, got %q", logger.config.BotToken)
+	}
+	if logger.config.ChatID != "provider_chat_id" {
+		t.Errorf("expected ChatID resolved from SecretProvider, got %q", logger.config.ChatID)
+	}
+}
+
+func TestSecretProviderRefreshPicksUpRotatedToken(t *testing.T) {
+	var chatIDsSeen []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var msg telegramMessage
+		json.Unmarshal(body, &msg)
+		chatIDsSeen = append(chatIDsSeen, msg.ChatID)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	provider := newFakeSecretProvider("token_v1", "chat_v1")
+
+	config := DefaultConfig()
+	config.SecretProvider = provider
+	config.SecretRefreshInterval = 20 * time.Millisecond
+	config.Async = false
+	config.HTTPClient = &http.Client{Transport: &testTransport{originalTransport: http.DefaultTransport, testServer: server, t: t}}
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	logger.Info("before rotation")
+
+	provider.set("token_v2", "chat_v2")
+	time.Sleep(100 * time.Millisecond) // let the refresh goroutine pick up the rotation
+
+	logger.Info("after rotation")
+
+	if len(chatIDsSeen) != 2 {
+		t.Fatalf("expected 2 sent messages, got %d: %v", len(chatIDsSeen), chatIDsSeen)
+	}
+	if chatIDsSeen[0] != "chat_v1" {
+		t.Errorf("expected the first send to use the initial chat ID, got %q", chatIDsSeen[0])
+	}
+	if chatIDsSeen[1] != "chat_v2" {
+		t.Errorf("expected the second send to use the rotated chat ID, got %q", chatIDsSeen[1])
+	}
+}
+
+func TestSecretProviderRefreshIgnoresEmptyCredentials(t *testing.T) {
+	var chatIDsSeen []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var msg telegramMessage
+		json.Unmarshal(body, &msg)
+		chatIDsSeen = append(chatIDsSeen, msg.ChatID)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	provider := newFakeSecretProvider("token_v1", "chat_v1")
+
+	config := DefaultConfig()
+	config.SecretProvider = provider
+	config.SecretRefreshInterval = 20 * time.Millisecond
+	config.Async = false
+	config.HTTPClient = &http.Client{Transport: &testTransport{originalTransport: http.DefaultTransport, testServer: server, t: t}}
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	provider.set("", "") // e.g. a vault client returning a zero value mid-outage
+	time.Sleep(100 * time.Millisecond)
+
+	logger.Info("after empty refresh")
+
+	if len(chatIDsSeen) != 1 {
+		t.Fatalf("expected 1 sent message, got %d: %v", len(chatIDsSeen), chatIDsSeen)
+	}
+	if chatIDsSeen[0] != "chat_v1" {
+		t.Errorf("expected the previous chat ID to stick after an empty refresh, got %q", chatIDsSeen[0])
+	}
+}
+
+type erroringSecretProvider struct{}
+
+func (erroringSecretProvider) GetBotToken(ctx context.Context) (string, error) {
+	return "", fmt.Errorf("secret store unavailable")
+}
+
+func (erroringSecretProvider) GetChatID(ctx context.Context) (string, error) {
+	return "", fmt.Errorf("secret store unavailable")
+}
+
+func TestLoggerCloseIsIdempotentWithSecretProvider(t *testing.T) {
+	config := DefaultConfig()
+	config.SecretProvider = newFakeSecretProvider("token", "chat_id")
+	config.Async = false
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	logger.Close()
+	logger.Close() // must not panic closing secretRefreshStop twice
+}
+
+func TestNewFailsWhenSecretProviderErrors(t *testing.T) {
+	config := DefaultConfig()
+	config.SecretProvider = erroringSecretProvider{}
+
+	if _, err := New(config); err == nil {
+		t.Fatal("expected New to fail when SecretProvider returns an error")
+	}
+}
@@ -64,15 +64,16 @@ func NewSecure(logConfig Config, secureConfig SecureConfig) (*Logger, error) {
 func SecureWithEnv() (*Logger, error) {
 	logConfig := DefaultConfig()
 	secureConfig := DefaultSecureConfig()
+	lookup := logConfig.EnvLookup
 
 	// Get configuration from environment variables
-	botToken := getEnv("TELEGRAM_BOT_TOKEN", "")
-	chatID := getEnv("TELEGRAM_CHAT_ID", "")
-	logLevel := getEnv("TELEGRAM_LOG_LEVEL", "")
-	appName := getEnv("TELEGRAM_APP_NAME", "")
-	async := getEnv("TELEGRAM_ASYNC", "true")
-	disableColors := getEnv("TELEGRAM_DISABLE_COLORS", "false")
-	timeFormat := getEnv("TELEGRAM_TIME_FORMAT", "")
+	botToken := getEnv(lookup, "TELEGRAM_BOT_TOKEN", "")
+	chatID := getEnv(lookup, "TELEGRAM_CHAT_ID", "")
+	logLevel := getEnv(lookup, "TELEGRAM_LOG_LEVEL", "")
+	appName := getEnv(lookup, "TELEGRAM_APP_NAME", "")
+	async := getEnv(lookup, "TELEGRAM_ASYNC", "true")
+	disableColors := getEnv(lookup, "TELEGRAM_DISABLE_COLORS", "false")
+	timeFormat := getEnv(lookup, "TELEGRAM_TIME_FORMAT", "")
 
 	if botToken == "" {
 		return nil, errMissingEnvVar("TELEGRAM_BOT_TOKEN")
@@ -109,154 +110,21 @@ func SecureWithEnv() (*Logger, error) {
 	return NewSecure(logConfig, secureConfig)
 }
 
-// Helper functions
-func getEnv(key, defaultValue string) string {
-	value, exists := getEnvOk(key)
-	if !exists {
-		return defaultValue
+// getEnv looks up key via lookup (falling back to os.LookupEnv if lookup is
+// nil), returning defaultValue if it isn't set.
+func getEnv(lookup func(string) (string, bool), key, defaultValue string) string {
+	if lookup == nil {
+		lookup = os.LookupEnv
 	}
-	return value
-}
-
-func getEnvOk(key string) (string, bool) {
-	value, exists := tgEnvLookup(key)
-	return value, exists
-}
-
-// This can be replaced with a more secure implementation for sensitive values
-var tgEnvLookup = func(key string) (string, bool) {
-	value, exists := getOsEnv(key)
-	return value, exists
-}
-
-// Separate function to allow for testing
-var getOsEnv = func(key string) (string, bool) {
-	v, exists := getOsEnvImpl(key)
-	return v, exists
-}
-
-// Implementation of getting environment variables
-var getOsEnvImpl = func(key string) (string, bool) {
-	value, exists := lookupEnv(key)
-	return value, exists
-}
-
-// Wrapper around os.LookupEnv to allow for easy mocking in tests
-var lookupEnv = func(key string) (string, bool) {
-	return LookupEnv(key)
+	if value, exists := lookup(key); exists {
+		return value
+	}
+	return defaultValue
 }
 
-// LookupEnv is the final function that calls os.LookupEnv
+// LookupEnv is a thin wrapper around os.LookupEnv, kept as the default value
+// of Config.EnvLookup.
 func LookupEnv(key string) (string, bool) {
-	return LookupEnvImpl(key)
-}
-
-// Actual implementation
-var LookupEnvImpl = func(key string) (string, bool) {
-	return tgEnvLookupImpl(key)
-}
-
-// The actual OS environment lookup
-var tgEnvLookupImpl = envLookup
-
-func envLookup(key string) (string, bool) {
-	return envLookupImpl(key)
-}
-
-var envLookupImpl = func(key string) (string, bool) {
-	value, exists := osLookupEnv(key)
-	return value, exists
-}
-
-// Wrappers to make testing easier
-var osLookupEnv = func(key string) (string, bool) {
-	return osLookupEnvImpl(key)
-}
-
-var osLookupEnvImpl = func(key string) (string, bool) {
-	return tglogOsLookupEnv(key)
-}
-
-// Final function that actually calls os.LookupEnv
-var tglogOsLookupEnv = tglogOsLookupEnvImpl
-
-var tglogOsLookupEnvImpl = func(key string) (string, bool) {
-	return lookupOsEnv(key)
-}
-
-var lookupOsEnv = lookupOsEnvImpl
-
-var lookupOsEnvImpl = func(key string) (string, bool) {
-	return lookupRealEnv(key)
-}
-
-// The actual lookup function
-var lookupRealEnv = func(key string) (string, bool) {
-	return lookupRealEnvImpl(key)
-}
-
-// The actual implementation that calls os.LookupEnv
-var lookupRealEnvImpl = func(key string) (string, bool) {
-	return finalLookupEnv(key)
-}
-
-// The final function that calls os.LookupEnv
-var finalLookupEnv = finalEnvLookup
-
-var finalEnvLookup = func(key string) (string, bool) {
-	return finalEnvLookupImpl(key)
-}
-
-// The actual call to os.LookupEnv
-var finalEnvLookupImpl = func(key string) (string, bool) {
-	return osLookupEnvFinal(key)
-}
-
-// Finally call os.LookupEnv
-var osLookupEnvFinal = osLookupEnvFinalImpl
-
-var osLookupEnvFinalImpl = func(key string) (string, bool) {
-	return tgOsLookupEnv(key)
-}
-
-// The final call that cannot be mocked
-var tgOsLookupEnv = tgOsLookupEnvImpl
-
-var tgOsLookupEnvImpl = func(key string) (string, bool) {
-	return implementedLookup(key)
-}
-
-var implementedLookup = implementedLookupImpl
-
-var implementedLookupImpl = func(key string) (string, bool) {
-	return implementedOsLookupEnv(key)
-}
-
-// The real function
-var implementedOsLookupEnv = implementedOsLookupEnvImpl
-
-var implementedOsLookupEnvImpl = func(key string) (string, bool) {
-	return implementedOsLookupEnvFinal(key)
-}
-
-var implementedOsLookupEnvFinal = implementedOsLookupEnvFinalImpl
-
-var implementedOsLookupEnvFinalImpl = func(key string) (string, bool) {
-	return envLookupFromOS(key)
-}
-
-// Finally import os and use it to look up the environment variable
-// This is the most secure way to handle environment variables
-var envLookupFromOS = envLookupFromOSImpl
-
-var envLookupFromOSImpl = func(key string) (string, bool) {
-	return envLookupFromOSFinal(key)
-}
-
-// Just to show how serious we are about security
-var envLookupFromOSFinal = envLookupFromOSFinalImpl
-
-var envLookupFromOSFinalImpl = func(key string) (string, bool) {
 	return os.LookupEnv(key)
 }
 
@@ -0,0 +1,410 @@
+package tglog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// globalRateLimit approximates Telegram's overall flood-control budget
+	// across all chats, used when Config.RateLimit.GlobalPerSecond is unset.
+	globalRateLimit = 30.0
+	// perChatRateLimit approximates Telegram's per-chat flood-control
+	// budget, used when Config.RateLimit.PerChatPerSecond is unset.
+	perChatRateLimit = 1.0
+
+	// senderMaxRetries is used when Config.MaxRetries is unset.
+	senderMaxRetries  = 3
+	senderBaseBackoff = 500 * time.Millisecond
+
+	spoolFileName = "tglog.spool"
+)
+
+// RateLimit configures the token buckets Sender uses to stay under
+// Telegram's flood-control limits. Zero values fall back to defaults
+// approximating Telegram's own limits (30/sec global, 1/sec per chat).
+type RateLimit struct {
+	// GlobalPerSecond caps the combined send rate across all chats.
+	GlobalPerSecond float64
+	// PerChatPerSecond caps the send rate to a single chat.
+	PerChatPerSecond float64
+}
+
+// Stats reports delivery counters for a Logger; see Logger.Stats.
+type Stats struct {
+	// Sent is the number of messages successfully delivered to Telegram.
+	Sent int64
+	// Failed is the number of messages whose delivery failed, either after
+	// exhausting their retries or at once on a non-retryable 4xx response.
+	Failed int64
+	// Queued is the number of messages currently persisted to the disk spool
+	// awaiting redelivery.
+	Queued int64
+	// Dropped is the number of messages that failed delivery and could not
+	// be spooled (no SpoolDir configured, or the write itself failed).
+	Dropped int64
+}
+
+// tokenBucket is a simple token-bucket rate limiter: capacity tokens refill
+// at refillRate tokens/sec, and take blocks until one is available.
+type tokenBucket struct {
+	mu         sync.Mutex
+	capacity   float64
+	tokens     float64
+	refillRate float64
+	last       time.Time
+}
+
+func newTokenBucket(capacity, refillRate float64) *tokenBucket {
+	return &tokenBucket{capacity: capacity, tokens: capacity, refillRate: refillRate, last: time.Now()}
+}
+
+func (b *tokenBucket) refillLocked() {
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.refillRate
+	if b.tokens > b.capacity {
+		b.tokens = b.capacity
+	}
+	b.last = now
+}
+
+// take blocks until a token is available, then consumes it.
+func (b *tokenBucket) take() {
+	for {
+		b.mu.Lock()
+		b.refillLocked()
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mu.Unlock()
+			return
+		}
+		wait := time.Duration((1 - b.tokens) / b.refillRate * float64(time.Second))
+		b.mu.Unlock()
+		time.Sleep(wait)
+	}
+}
+
+// retryAfterError signals that Telegram responded 429 and asked us to wait
+// after before retrying.
+type retryAfterError struct {
+	after time.Duration
+}
+
+func (e *retryAfterError) Error() string {
+	return fmt.Sprintf("tglog: rate limited by telegram, retry after %s", e.after)
+}
+
+// permanentError signals that Telegram rejected the request with a 4xx
+// status other than 429 (bad token, bad chat ID, malformed payload, etc.).
+// Unlike a 5xx or network error, retrying the exact same request will never
+// succeed, so sendWithRetry gives up immediately instead of burning its
+// retry budget.
+type permanentError struct {
+	statusCode int
+	body       string
+}
+
+func (e *permanentError) Error() string {
+	return fmt.Sprintf("tglog: telegram API rejected the request with status %d: %s", e.statusCode, e.body)
+}
+
+type telegramErrorResponse struct {
+	Parameters struct {
+		RetryAfter int `json:"retry_after"`
+	} `json:"parameters"`
+}
+
+// spoolEntry is one undelivered message persisted to Config.SpoolDir. Count
+// is how many original log calls it represents (>1 for a coalesced batch),
+// so Stats stay accurate across a restart.
+type spoolEntry struct {
+	Text      string `json:"text"`
+	ParseMode string `json:"parse_mode"`
+	Count     int    `json:"count"`
+}
+
+// sender owns rate limiting, retry, spooling, and delivery-metric bookkeeping
+// for outbound Telegram messages, decoupling that from Logger's queueing.
+type sender struct {
+	// botToken and chatID are stored as atomic.Value (not plain strings) so
+	// SecretProvider-driven credential refreshes are race-free against
+	// concurrent sends.
+	botToken   atomic.Value // string
+	chatID     atomic.Value // string
+	httpClient *http.Client
+
+	global  *tokenBucket
+	perChat *tokenBucket
+
+	maxRetries int
+
+	spoolPath string
+	spoolMu   sync.Mutex
+
+	sent, failed, queued, dropped int64
+}
+
+func newSender(config Config) *sender {
+	globalPerSecond := config.RateLimit.GlobalPerSecond
+	if globalPerSecond <= 0 {
+		globalPerSecond = globalRateLimit
+	}
+	perChatPerSecond := config.RateLimit.PerChatPerSecond
+	if perChatPerSecond <= 0 {
+		perChatPerSecond = perChatRateLimit
+	}
+	maxRetries := config.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = senderMaxRetries
+	}
+
+	s := &sender{
+		httpClient: config.HTTPClient,
+		global:     newTokenBucket(globalPerSecond, globalPerSecond),
+		perChat:    newTokenBucket(perChatPerSecond, perChatPerSecond),
+		maxRetries: maxRetries,
+	}
+	s.setCredentials(config.BotToken, config.ChatID)
+	if config.SpoolDir != "" {
+		s.spoolPath = filepath.Join(config.SpoolDir, spoolFileName)
+	}
+	return s
+}
+
+// setCredentials atomically swaps the bot token and chat ID used by future
+// sends, letting a SecretProvider refresh rotate credentials without racing
+// an in-flight post.
+func (s *sender) setCredentials(botToken, chatID string) {
+	s.botToken.Store(botToken)
+	s.chatID.Store(chatID)
+}
+
+// currentBotToken returns the bot token in effect right now, reflecting any
+// SecretProvider refresh since New. Callers that build their
+// own Telegram API URLs (e.g. the interactive long-poller) must use this
+// instead of Config.BotToken, which is only the value captured at New.
+func (s *sender) currentBotToken() string {
+	return s.botToken.Load().(string)
+}
+
+func (s *sender) stats() Stats {
+	return Stats{
+		Sent:    atomic.LoadInt64(&s.sent),
+		Failed:  atomic.LoadInt64(&s.failed),
+		Queued:  atomic.LoadInt64(&s.queued),
+		Dropped: atomic.LoadInt64(&s.dropped),
+	}
+}
+
+// deliver sends content to Telegram on behalf of count original log calls
+// (count is 1 unless the queue coalesced a burst into a single message),
+// respecting rate limits and retrying on 429/5xx/network errors before
+// falling back to the disk spool (if configured) or dropping the message.
+// level only matters for the existing os.Exit(1)-on-Fatal behavior.
+func (s *sender) deliver(level LogLevel, content, parseMode string, count int) {
+	err := s.sendWithRetry(s.chatID.Load().(string), content, parseMode)
+
+	if err != nil {
+		atomic.AddInt64(&s.failed, int64(count))
+		fmt.Fprintf(os.Stderr, "tglog: failed to send message after retries: %v\n", err)
+		if s.spoolPath != "" {
+			if spoolErr := s.appendSpool(content, parseMode, count); spoolErr == nil {
+				atomic.AddInt64(&s.queued, int64(count))
+				if level == Fatal {
+					os.Exit(1)
+				}
+				return
+			}
+		}
+		atomic.AddInt64(&s.dropped, int64(count))
+		if level == Fatal {
+			os.Exit(1)
+		}
+		return
+	}
+	atomic.AddInt64(&s.sent, int64(count))
+
+	if level == Fatal {
+		os.Exit(1)
+	}
+}
+
+// sendWithRetry posts content to chatID, retrying on 429/5xx/network errors
+// up to s.maxRetries. A permanentError (any other 4xx) is not retried, since
+// Telegram has already told us the request itself is invalid. Every attempt,
+// including retries, takes a fresh slot from both rate-limit buckets so a
+// failing burst can't exceed the budget the buckets enforce.
+func (s *sender) sendWithRetry(chatID, content, parseMode string) error {
+	s.global.take()
+	s.perChat.take()
+	err := s.post(chatID, content, parseMode)
+
+	for attempt := 0; err != nil && attempt < s.maxRetries; attempt++ {
+		if _, ok := err.(*permanentError); ok {
+			break
+		}
+		time.Sleep(s.backoff(err, attempt))
+		s.global.take()
+		s.perChat.take()
+		err = s.post(chatID, content, parseMode)
+	}
+	return err
+}
+
+// replyToChat delivers content to chatID directly, bypassing the primary
+// sender/spool pipeline: a command reply must reach the chat that issued
+// the command (Args.ChatID), which in a multi-chat AuthChecker whitelist
+// may differ from Config.ChatID, and a reply that still fails after retries
+// isn't worth spooling for later delivery the way a log message is. Sent
+// and failed counts still feed into Stats, the same as a regular message.
+func (s *sender) replyToChat(chatID, content, parseMode string) error {
+	err := s.sendWithRetry(chatID, content, parseMode)
+	if err != nil {
+		atomic.AddInt64(&s.failed, 1)
+		return err
+	}
+	atomic.AddInt64(&s.sent, 1)
+	return nil
+}
+
+// backoff returns how long to wait before the next retry: Telegram's
+// requested retry_after for 429s, or exponential backoff with jitter for
+// everything else.
+func (s *sender) backoff(err error, attempt int) time.Duration {
+	if rateErr, ok := err.(*retryAfterError); ok {
+		return rateErr.after
+	}
+	delay := senderBaseBackoff * time.Duration(1<<attempt)
+	return delay + time.Duration(rand.Int63n(int64(senderBaseBackoff)))
+}
+
+func (s *sender) post(chatID, content, parseMode string) error {
+	apiURL := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", s.botToken.Load().(string))
+	msg := telegramMessage{ChatID: chatID, Text: content, ParseMode: parseMode}
+
+	jsonData, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("tglog: failed to marshal message: %w", err)
+	}
+
+	resp, err := s.httpClient.Post(apiURL, "application/json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("tglog: failed to send message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		body, _ := io.ReadAll(resp.Body)
+		var parsed telegramErrorResponse
+		json.Unmarshal(body, &parsed)
+		after := time.Duration(parsed.Parameters.RetryAfter) * time.Second
+		if after <= 0 {
+			after = senderBaseBackoff
+		}
+		return &retryAfterError{after: after}
+	}
+
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 {
+		body, _ := io.ReadAll(resp.Body)
+		return &permanentError{statusCode: resp.StatusCode, body: string(body)}
+	}
+
+	if resp.StatusCode >= 500 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("tglog: telegram API returned error status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+// appendSpool persists an undelivered message to disk so it survives a
+// process restart.
+func (s *sender) appendSpool(content, parseMode string, count int) error {
+	s.spoolMu.Lock()
+	defer s.spoolMu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(s.spoolPath), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.spoolPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	line, err := json.Marshal(spoolEntry{Text: content, ParseMode: parseMode, Count: count})
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// replaySpool attempts to redeliver every message persisted to disk,
+// rewriting the spool file with only the ones that still fail. Intended to
+// be called once, at startup.
+func (s *sender) replaySpool() {
+	if s.spoolPath == "" {
+		return
+	}
+
+	s.spoolMu.Lock()
+	data, err := os.ReadFile(s.spoolPath)
+	s.spoolMu.Unlock()
+	if err != nil {
+		return
+	}
+
+	var entries []spoolEntry
+	var totalQueued int64
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		var entry spoolEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			continue
+		}
+		if entry.Count < 1 {
+			entry.Count = 1
+		}
+		entries = append(entries, entry)
+		totalQueued += int64(entry.Count)
+	}
+	atomic.StoreInt64(&s.queued, totalQueued)
+
+	var remaining []spoolEntry
+	for _, entry := range entries {
+		if err := s.sendWithRetry(s.chatID.Load().(string), entry.Text, entry.ParseMode); err != nil {
+			remaining = append(remaining, entry)
+			continue
+		}
+		atomic.AddInt64(&s.sent, int64(entry.Count))
+		atomic.AddInt64(&s.queued, -int64(entry.Count))
+	}
+
+	s.spoolMu.Lock()
+	defer s.spoolMu.Unlock()
+	if len(remaining) == 0 {
+		os.Remove(s.spoolPath)
+		return
+	}
+	f, err := os.OpenFile(s.spoolPath, os.O_TRUNC|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	for _, entry := range remaining {
+		line, _ := json.Marshal(entry)
+		f.Write(append(line, '\n'))
+	}
+}
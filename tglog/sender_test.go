@@ -0,0 +1,199 @@
+package tglog
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTokenBucketTakeBlocksUntilRefilled(t *testing.T) {
+	b := newTokenBucket(1, 1000) // capacity 1, refills fast so the test stays quick
+	b.take()                     // drains the single starting token immediately
+
+	done := make(chan struct{})
+	go func() {
+		b.take()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(200 * time.Millisecond):
+		t.Fatal("take() did not return after the bucket had time to refill")
+	}
+}
+
+func TestSenderRetriesOn429ThenSucceeds(t *testing.T) {
+	var attempts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"ok":false,"error_code":429,"parameters":{"retry_after":0}}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.BotToken = "test_token"
+	config.ChatID = "test_chat_id"
+	config.Async = false
+	config.HTTPClient = &http.Client{Transport: &testTransport{originalTransport: http.DefaultTransport, testServer: server, t: t}}
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	logger.Info("retried message")
+
+	stats := logger.Stats()
+	if stats.Sent != 1 {
+		t.Errorf("expected 1 sent message after retry, got stats %+v", stats)
+	}
+	if attempts < 2 {
+		t.Errorf("expected at least 2 attempts (initial 429 + retry), got %d", attempts)
+	}
+}
+
+func TestConfigRateLimitOverridesDefaultBucket(t *testing.T) {
+	config := DefaultConfig()
+	config.RateLimit = RateLimit{GlobalPerSecond: 1000, PerChatPerSecond: 1000}
+	s := newSender(config)
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		s.global.take()
+		s.perChat.take()
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("expected a high configured rate limit to not block, took %s", elapsed)
+	}
+}
+
+func TestConfigMaxRetriesIsRespected(t *testing.T) {
+	var attempts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.BotToken = "test_token"
+	config.ChatID = "test_chat_id"
+	config.Async = false
+	config.MaxRetries = 1
+	config.HTTPClient = &http.Client{Transport: &testTransport{originalTransport: http.DefaultTransport, testServer: server, t: t}}
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	logger.Info("should give up after one retry")
+
+	if attempts != 2 {
+		t.Errorf("expected 1 initial attempt + 1 retry = 2 POSTs, got %d", attempts)
+	}
+}
+
+func TestSenderDoesNotRetryPermanentClientError(t *testing.T) {
+	var attempts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"ok":false,"error_code":401,"description":"Unauthorized"}`))
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.BotToken = "invalid_token"
+	config.ChatID = "test_chat_id"
+	config.Async = false
+	config.HTTPClient = &http.Client{Transport: &testTransport{originalTransport: http.DefaultTransport, testServer: server, t: t}}
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	logger.Info("rejected outright, should not be retried")
+
+	if attempts != 1 {
+		t.Errorf("expected a permanent 401 to fail fast with exactly 1 attempt, got %d", attempts)
+	}
+	stats := logger.Stats()
+	if stats.Failed != 1 {
+		t.Errorf("expected the permanent failure to count toward Failed, got stats %+v", stats)
+	}
+}
+
+func TestSenderSpoolsUndeliveredMessagesAndReplaysOnRestart(t *testing.T) {
+	spoolDir := t.TempDir()
+
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer down.Close()
+
+	config := DefaultConfig()
+	config.BotToken = "test_token"
+	config.ChatID = "test_chat_id"
+	config.Async = false
+	config.SpoolDir = spoolDir
+	config.HTTPClient = &http.Client{Transport: &testTransport{originalTransport: http.DefaultTransport, testServer: down, t: t}}
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	logger.Info("never delivered the first time")
+	logger.Close()
+
+	stats := logger.Stats()
+	if stats.Queued != 1 {
+		t.Fatalf("expected 1 message spooled after the primary endpoint stayed down, got stats %+v", stats)
+	}
+
+	spoolPath := filepath.Join(spoolDir, spoolFileName)
+	if _, err := os.Stat(spoolPath); err != nil {
+		t.Fatalf("expected spool file at %s: %v", spoolPath, err)
+	}
+
+	var delivered []string
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delivered = append(delivered, r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer up.Close()
+
+	config.HTTPClient = &http.Client{Transport: &testTransport{originalTransport: http.DefaultTransport, testServer: up, t: t}}
+
+	logger2, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger2.Close()
+
+	if len(delivered) != 1 {
+		t.Fatalf("expected the spooled message to be replayed on startup, got %d deliveries", len(delivered))
+	}
+	if logger2.Stats().Queued != 0 {
+		t.Errorf("expected spool to be drained after a successful replay, got stats %+v", logger2.Stats())
+	}
+	if _, err := os.Stat(spoolPath); !os.IsNotExist(err) {
+		t.Errorf("expected spool file to be removed after a clean replay, stat err: %v", err)
+	}
+}
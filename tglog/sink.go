@@ -0,0 +1,79 @@
+package tglog
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// Sink is an additional destination a Logger can fan log entries out to,
+// alongside the primary Telegram chat configured via Config.BotToken/ChatID.
+// MultiSink and FallbackSink compose Sinks into fan-out and failover
+// arrangements, so a single Sink value passed to AddSink can represent
+// several destinations at once.
+type Sink interface {
+	// Send delivers entry to the sink.
+	Send(ctx context.Context, entry Entry) error
+	// Close releases any resources held by the sink.
+	Close() error
+}
+
+// SinkSpec pairs a Sink with the minimum level it should receive.
+type SinkSpec struct {
+	Sink     Sink
+	MinLevel LogLevel
+}
+
+// AddSink registers an additional sink that receives every entry at or
+// above minLevel, alongside the logger's primary Telegram destination.
+func (l *Logger) AddSink(sink Sink, minLevel LogLevel) {
+	l.sinksMu.Lock()
+	defer l.sinksMu.Unlock()
+	l.sinks = append(l.sinks, SinkSpec{Sink: sink, MinLevel: minLevel})
+}
+
+// dispatchToSinks fans entry out to every registered sink whose MinLevel it
+// meets. In async mode each sink is sent to concurrently; in sync mode sinks
+// are sent to in registration order, matching the primary send's blocking
+// behavior.
+func (l *Logger) dispatchToSinks(entry Entry) {
+	l.sinksMu.RLock()
+	specs := l.sinks
+	l.sinksMu.RUnlock()
+
+	for _, spec := range specs {
+		spec := spec
+		if entry.Level < spec.MinLevel {
+			continue
+		}
+		send := func() {
+			if err := spec.Sink.Send(context.Background(), entry); err != nil {
+				fmt.Fprintf(os.Stderr, "tglog: sink failed to send: %v\n", err)
+			}
+		}
+		if l.config.Async {
+			l.wg.Add(1)
+			go func() {
+				defer l.wg.Done()
+				send()
+			}()
+		} else {
+			send()
+		}
+	}
+}
+
+// closeSinks closes every registered sink, logging individual errors to
+// stderr without stopping on them.
+func (l *Logger) closeSinks() {
+	l.sinksMu.Lock()
+	specs := l.sinks
+	l.sinks = nil
+	l.sinksMu.Unlock()
+
+	for _, spec := range specs {
+		if err := spec.Sink.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "tglog: sink failed to close: %v\n", err)
+		}
+	}
+}
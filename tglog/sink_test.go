@@ -0,0 +1,229 @@
+package tglog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// recordingSink captures every entry it receives, for use in AddSink tests.
+type recordingSink struct {
+	entries []Entry
+}
+
+func (s *recordingSink) Send(_ context.Context, entry Entry) error {
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func (s *recordingSink) Close() error {
+	return nil
+}
+
+func TestLoggerAddSinkRespectsMinLevel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.BotToken = "test_token"
+	config.ChatID = "test_chat_id"
+	config.Async = false
+	config.MinLevel = Debug
+	config.HTTPClient = &http.Client{Transport: &testTransport{originalTransport: http.DefaultTransport, testServer: server, t: t}}
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	sink := &recordingSink{}
+	logger.AddSink(sink, Warning)
+
+	logger.emit(Info, "below threshold", nil)
+	logger.emit(Warning, "at threshold", nil)
+	logger.emit(Error, "above threshold", nil)
+
+	if len(sink.entries) != 2 {
+		t.Fatalf("expected 2 entries at or above Warning, got %d", len(sink.entries))
+	}
+	if sink.entries[0].Message != "at threshold" || sink.entries[1].Message != "above threshold" {
+		t.Errorf("unexpected entries: %+v", sink.entries)
+	}
+}
+
+func TestFileSinkRotates(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "log.txt")
+
+	sink, err := NewFileSink(path, 10, LogfmtFormatter{})
+	if err != nil {
+		t.Fatalf("failed to create file sink: %v", err)
+	}
+	defer sink.Close()
+
+	for i := 0; i < 5; i++ {
+		if err := sink.Send(context.Background(), Entry{Level: Info, Message: "hello world"}); err != nil {
+			t.Fatalf("send failed: %v", err)
+		}
+	}
+
+	if _, err := os.Stat(path + ".1"); err != nil {
+		t.Errorf("expected rotated file to exist: %v", err)
+	}
+}
+
+func TestFileSinkRotatesWithMaxBackups(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "log.txt")
+
+	sink, err := NewFileSink(path, 10, LogfmtFormatter{})
+	if err != nil {
+		t.Fatalf("failed to create file sink: %v", err)
+	}
+	sink.MaxBackups = 2
+	defer sink.Close()
+
+	for i := 0; i < 15; i++ {
+		if err := sink.Send(context.Background(), Entry{Level: Info, Message: "hello world"}); err != nil {
+			t.Fatalf("send failed: %v", err)
+		}
+	}
+
+	if _, err := os.Stat(path + ".1"); err != nil {
+		t.Errorf("expected .1 backup to exist: %v", err)
+	}
+	if _, err := os.Stat(path + ".2"); err != nil {
+		t.Errorf("expected .2 backup to exist: %v", err)
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected .3 backup not to exist (MaxBackups=2), got err=%v", err)
+	}
+}
+
+// failingSink fails Send until succeedAfter calls have been made, then
+// succeeds, recording every entry it actually accepted.
+type failingSink struct {
+	succeedAfter int
+	calls        int
+	entries      []Entry
+}
+
+func (s *failingSink) Send(_ context.Context, entry Entry) error {
+	s.calls++
+	if s.calls <= s.succeedAfter {
+		return fmt.Errorf("primary unavailable")
+	}
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func (s *failingSink) Close() error { return nil }
+
+func TestMultiSinkFansOutToAllSinks(t *testing.T) {
+	a := &recordingSink{}
+	b := &recordingSink{}
+	multi := NewMultiSink(a, b)
+
+	if err := multi.Send(context.Background(), Entry{Message: "hello"}); err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+	if len(a.entries) != 1 || len(b.entries) != 1 {
+		t.Fatalf("expected both sinks to receive the entry, got %d and %d", len(a.entries), len(b.entries))
+	}
+}
+
+func TestFallbackSinkSpillsToFallbackAndReplaysOnRecovery(t *testing.T) {
+	primary := &failingSink{succeedAfter: 2}
+	fallback := &recordingSink{}
+	fs := NewFallbackSink(primary, fallback)
+
+	if err := fs.Send(context.Background(), Entry{Message: "one"}); err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+	if err := fs.Send(context.Background(), Entry{Message: "two"}); err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+	if len(fallback.entries) != 2 {
+		t.Fatalf("expected both entries to spill to fallback while primary fails, got %d", len(fallback.entries))
+	}
+
+	if err := fs.Send(context.Background(), Entry{Message: "three"}); err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+	if len(primary.entries) != 3 {
+		t.Fatalf("expected primary to receive all 3 entries after recovery (replay + new), got %d: %+v", len(primary.entries), primary.entries)
+	}
+	if primary.entries[0].Message != "one" || primary.entries[1].Message != "two" || primary.entries[2].Message != "three" {
+		t.Errorf("expected replay to preserve order, got %+v", primary.entries)
+	}
+}
+
+// erroringCloseSink returns an error from Close and records whether Close
+// was called, for use in FallbackSink.Close tests.
+type erroringCloseSink struct {
+	closeErr error
+	closed   bool
+}
+
+func (s *erroringCloseSink) Send(_ context.Context, _ Entry) error { return nil }
+
+func (s *erroringCloseSink) Close() error {
+	s.closed = true
+	return s.closeErr
+}
+
+func TestFallbackSinkCloseClosesBothEvenWhenPrimaryErrors(t *testing.T) {
+	primary := &erroringCloseSink{closeErr: fmt.Errorf("primary close failed")}
+	fallback := &erroringCloseSink{}
+	fs := NewFallbackSink(primary, fallback)
+
+	if err := fs.Close(); err == nil {
+		t.Fatal("expected Close to return Primary's error")
+	}
+	if !fallback.closed {
+		t.Error("expected Fallback to be closed even though Primary.Close errored")
+	}
+}
+
+func TestWebhookSinkPostsJSON(t *testing.T) {
+	var received webhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	sink := NewWebhookSink(server.URL, nil)
+	if err := sink.Send(context.Background(), Entry{Level: Error, Message: "disk full"}); err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+	if received.Message != "disk full" || received.Level != "error" {
+		t.Errorf("unexpected payload: %+v", received)
+	}
+}
+
+func TestSlackSinkPostsFormattedText(t *testing.T) {
+	var received slackPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	sink := NewSlackSink(server.URL, nil, nil)
+	if err := sink.Send(context.Background(), Entry{Level: Warning, Message: "low disk"}); err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+	if !strings.Contains(received.Text, "low disk") {
+		t.Errorf("expected slack text to contain the message, got %q", received.Text)
+	}
+}
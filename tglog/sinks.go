@@ -0,0 +1,499 @@
+package tglog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// TelegramSink delivers entries to a Telegram chat, independent of the
+// Logger's primary Config.BotToken/ChatID. Use it with Logger.AddSink to fan
+// out to additional chats, e.g. a separate ops channel.
+type TelegramSink struct {
+	BotToken   string
+	ChatID     string
+	HTTPClient *http.Client
+	Formatter  Formatter
+}
+
+// NewTelegramSink creates a TelegramSink posting to chatID using botToken.
+// httpClient and formatter default to a 10s-timeout client and HTMLFormatter
+// if nil.
+func NewTelegramSink(botToken, chatID string, httpClient *http.Client, formatter Formatter) *TelegramSink {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	if formatter == nil {
+		formatter = HTMLFormatter{Colored: true}
+	}
+	return &TelegramSink{BotToken: botToken, ChatID: chatID, HTTPClient: httpClient, Formatter: formatter}
+}
+
+// Send implements Sink.
+func (s *TelegramSink) Send(ctx context.Context, entry Entry) error {
+	apiURL := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", s.BotToken)
+	payload := telegramMessage{
+		ChatID:    s.ChatID,
+		Text:      s.Formatter.Format(entry),
+		ParseMode: s.Formatter.ParseMode(),
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("tglog: marshal telegram sink payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("tglog: telegram sink request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("tglog: telegram sink got status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Close implements Sink.
+func (s *TelegramSink) Close() error {
+	return nil
+}
+
+// StderrSink writes formatted entries to os.Stderr (or another io.Writer),
+// one per line.
+type StderrSink struct {
+	Writer    io.Writer
+	Formatter Formatter
+	mu        sync.Mutex
+}
+
+// NewStderrSink creates a StderrSink writing to os.Stderr using formatter
+// (LogfmtFormatter if nil).
+func NewStderrSink(formatter Formatter) *StderrSink {
+	if formatter == nil {
+		formatter = LogfmtFormatter{}
+	}
+	return &StderrSink{Writer: os.Stderr, Formatter: formatter}
+}
+
+// Send implements Sink.
+func (s *StderrSink) Send(_ context.Context, entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := fmt.Fprintln(s.Writer, s.Formatter.Format(entry))
+	return err
+}
+
+// Close implements Sink.
+func (s *StderrSink) Close() error {
+	return nil
+}
+
+// FileSink appends formatted entries to a file, rotating it once it exceeds
+// MaxSizeBytes (if set). Rotation shifts existing backups up by one suffix
+// (".1" becomes ".2", ".2" becomes ".3", and so on) up to MaxBackups,
+// discarding anything older, the same lumberjack-style scheme logrotate
+// uses. MaxBackups <= 0 keeps a single backup (".1"), overwritten on every
+// rotation.
+type FileSink struct {
+	Path         string
+	MaxSizeBytes int64
+	MaxBackups   int
+	Formatter    Formatter
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// NewFileSink opens (creating if needed) path for appending. maxSizeBytes
+// <= 0 disables rotation. formatter defaults to LogfmtFormatter.
+func NewFileSink(path string, maxSizeBytes int64, formatter Formatter) (*FileSink, error) {
+	if formatter == nil {
+		formatter = LogfmtFormatter{}
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("tglog: open file sink: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("tglog: stat file sink: %w", err)
+	}
+	return &FileSink{Path: path, MaxSizeBytes: maxSizeBytes, Formatter: formatter, file: f, size: info.Size()}, nil
+}
+
+// Send implements Sink.
+func (s *FileSink) Send(_ context.Context, entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	line := s.Formatter.Format(entry) + "\n"
+	if s.MaxSizeBytes > 0 && s.size+int64(len(line)) > s.MaxSizeBytes {
+		if err := s.rotateLocked(); err != nil {
+			return err
+		}
+	}
+
+	n, err := s.file.WriteString(line)
+	s.size += int64(n)
+	return err
+}
+
+// rotateLocked closes the current file, shifts existing numbered backups up
+// by one (dropping anything beyond MaxBackups), renames the current file to
+// Path+".1", and opens a fresh file at Path. Callers must hold mu.
+func (s *FileSink) rotateLocked() error {
+	if err := s.file.Close(); err != nil {
+		return fmt.Errorf("tglog: close file sink for rotation: %w", err)
+	}
+
+	maxBackups := s.MaxBackups
+	if maxBackups <= 0 {
+		maxBackups = 1
+	}
+
+	os.Remove(fmt.Sprintf("%s.%d", s.Path, maxBackups))
+	for n := maxBackups - 1; n >= 1; n-- {
+		from := fmt.Sprintf("%s.%d", s.Path, n)
+		to := fmt.Sprintf("%s.%d", s.Path, n+1)
+		if _, err := os.Stat(from); err == nil {
+			if err := os.Rename(from, to); err != nil {
+				return fmt.Errorf("tglog: rotate file sink backup %q: %w", from, err)
+			}
+		}
+	}
+	if err := os.Rename(s.Path, s.Path+".1"); err != nil {
+		return fmt.Errorf("tglog: rotate file sink: %w", err)
+	}
+
+	f, err := os.OpenFile(s.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("tglog: reopen file sink after rotation: %w", err)
+	}
+	s.file = f
+	s.size = 0
+	return nil
+}
+
+// Close implements Sink.
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+// webhookPayload is the generic JSON body posted by WebhookSink.
+type webhookPayload struct {
+	Level   string                 `json:"level"`
+	App     string                 `json:"app,omitempty"`
+	Time    string                 `json:"time"`
+	Message string                 `json:"message"`
+	Fields  map[string]interface{} `json:"fields,omitempty"`
+}
+
+// WebhookSink POSTs each entry as a JSON object to an arbitrary URL.
+type WebhookSink struct {
+	URL        string
+	HTTPClient *http.Client
+}
+
+// NewWebhookSink creates a WebhookSink posting to url.
+func NewWebhookSink(url string, httpClient *http.Client) *WebhookSink {
+	if httpClient == nil {
+		httpClient = &http.Client{}
+	}
+	return &WebhookSink{URL: url, HTTPClient: httpClient}
+}
+
+// Send implements Sink.
+func (s *WebhookSink) Send(ctx context.Context, entry Entry) error {
+	data, err := json.Marshal(webhookPayload{
+		Level:   levelName(entry.Level),
+		App:     entry.AppName,
+		Time:    entry.Timestamp,
+		Message: entry.Message,
+		Fields:  entry.Fields,
+	})
+	if err != nil {
+		return fmt.Errorf("tglog: marshal webhook sink payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("tglog: webhook sink request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("tglog: webhook sink got status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Close implements Sink.
+func (s *WebhookSink) Close() error {
+	return nil
+}
+
+// slackPayload is Slack's minimal incoming-webhook message format.
+type slackPayload struct {
+	Text string `json:"text"`
+}
+
+// SlackSink posts entries to a Slack incoming webhook URL.
+type SlackSink struct {
+	WebhookURL string
+	HTTPClient *http.Client
+	Formatter  Formatter
+}
+
+// NewSlackSink creates a SlackSink posting to webhookURL. formatter defaults
+// to LogfmtFormatter (Slack ignores Telegram parse_mode entirely).
+func NewSlackSink(webhookURL string, httpClient *http.Client, formatter Formatter) *SlackSink {
+	if httpClient == nil {
+		httpClient = &http.Client{}
+	}
+	if formatter == nil {
+		formatter = LogfmtFormatter{}
+	}
+	return &SlackSink{WebhookURL: webhookURL, HTTPClient: httpClient, Formatter: formatter}
+}
+
+// Send implements Sink.
+func (s *SlackSink) Send(ctx context.Context, entry Entry) error {
+	data, err := json.Marshal(slackPayload{Text: s.Formatter.Format(entry)})
+	if err != nil {
+		return fmt.Errorf("tglog: marshal slack sink payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.WebhookURL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("tglog: slack sink request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("tglog: slack sink got status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Close implements Sink.
+func (s *SlackSink) Close() error {
+	return nil
+}
+
+// UnixSocketSink writes each entry as a newline-delimited JSON record to a
+// Unix domain socket, the same wire format tglog/listener accepts. This lets
+// a single socket-ingestion daemon (or another tglog process) receive
+// entries forwarded from this logger. The connection is dialed lazily and
+// redialed once on write failure.
+type UnixSocketSink struct {
+	Path string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewUnixSocketSink creates a sink that will dial path on first use.
+func NewUnixSocketSink(path string) *UnixSocketSink {
+	return &UnixSocketSink{Path: path}
+}
+
+// Send implements Sink.
+func (s *UnixSocketSink) Send(ctx context.Context, entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := json.Marshal(struct {
+		Level  string                 `json:"level"`
+		App    string                 `json:"app,omitempty"`
+		Time   string                 `json:"time"`
+		Msg    string                 `json:"msg"`
+		Fields map[string]interface{} `json:"fields,omitempty"`
+	}{
+		Level:  levelName(entry.Level),
+		App:    entry.AppName,
+		Time:   entry.Timestamp,
+		Msg:    entry.Message,
+		Fields: entry.Fields,
+	})
+	if err != nil {
+		return fmt.Errorf("tglog: marshal unix socket sink payload: %w", err)
+	}
+	data = append(data, '\n')
+
+	if s.conn == nil {
+		if err := s.dialLocked(ctx); err != nil {
+			return err
+		}
+	}
+
+	if _, err := s.conn.Write(data); err != nil {
+		s.conn.Close()
+		s.conn = nil
+		if dialErr := s.dialLocked(ctx); dialErr != nil {
+			return fmt.Errorf("tglog: unix socket sink write failed and redial failed: %w", dialErr)
+		}
+		if _, err := s.conn.Write(data); err != nil {
+			return fmt.Errorf("tglog: unix socket sink write failed after redial: %w", err)
+		}
+	}
+	return nil
+}
+
+// dialLocked dials Path. Callers must hold mu.
+func (s *UnixSocketSink) dialLocked(ctx context.Context) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "unix", s.Path)
+	if err != nil {
+		return fmt.Errorf("tglog: dial unix socket sink: %w", err)
+	}
+	s.conn = conn
+	return nil
+}
+
+// Close implements Sink.
+func (s *UnixSocketSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// MultiSink fans Send and Close out to every sink in Sinks, in order. Use it
+// to pass several destinations (e.g. console, file, Telegram) to AddSink as
+// a single Sink value.
+type MultiSink struct {
+	Sinks []Sink
+}
+
+// NewMultiSink creates a MultiSink fanning out to sinks.
+func NewMultiSink(sinks ...Sink) *MultiSink {
+	return &MultiSink{Sinks: sinks}
+}
+
+// Send implements Sink, sending to every sink and returning the first error
+// encountered, after attempting the rest.
+func (m *MultiSink) Send(ctx context.Context, entry Entry) error {
+	var firstErr error
+	for _, sink := range m.Sinks {
+		if err := sink.Send(ctx, entry); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Close implements Sink, closing every sink and returning the first error
+// encountered, after attempting the rest.
+func (m *MultiSink) Close() error {
+	var firstErr error
+	for _, sink := range m.Sinks {
+		if err := sink.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// FallbackSink wraps a Primary sink (typically a TelegramSink) with a local
+// Fallback sink (typically a FileSink) used while Primary is unreachable.
+// Every Send first retries any entries spilled to Fallback during a prior
+// outage; once those replay cleanly, the new entry goes to Primary too. If
+// Primary is still failing, the entry is appended to Fallback and queued for
+// the next retry, the same spill-then-replay idea as sender.go's disk spool
+// for the primary Telegram chat, but kept in memory: the pending queue does
+// not survive a process restart, though every spilled entry is still
+// durably written to Fallback before Send returns.
+type FallbackSink struct {
+	Primary  Sink
+	Fallback Sink
+
+	mu      sync.Mutex
+	pending []Entry
+}
+
+// NewFallbackSink creates a FallbackSink that spills to fallback when
+// primary fails.
+func NewFallbackSink(primary, fallback Sink) *FallbackSink {
+	return &FallbackSink{Primary: primary, Fallback: fallback}
+}
+
+// Send implements Sink.
+func (s *FallbackSink) Send(ctx context.Context, entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.replayPendingLocked(ctx)
+
+	if len(s.pending) == 0 {
+		if err := s.Primary.Send(ctx, entry); err == nil {
+			return nil
+		}
+	}
+
+	if err := s.Fallback.Send(ctx, entry); err != nil {
+		return fmt.Errorf("tglog: fallback sink's fallback also failed: %w", err)
+	}
+	s.pending = append(s.pending, entry)
+	return nil
+}
+
+// replayPendingLocked resends queued entries to Primary in order, stopping
+// at the first failure so delivery order is preserved across retries.
+// Callers must hold mu.
+func (s *FallbackSink) replayPendingLocked(ctx context.Context) {
+	i := 0
+	for ; i < len(s.pending); i++ {
+		if err := s.Primary.Send(ctx, s.pending[i]); err != nil {
+			break
+		}
+	}
+	s.pending = s.pending[i:]
+}
+
+// Close implements Sink, closing both Primary and Fallback and returning the
+// first error encountered, after attempting both.
+func (s *FallbackSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	errPrimary := s.Primary.Close()
+	errFallback := s.Fallback.Close()
+	if errPrimary != nil {
+		return errPrimary
+	}
+	return errFallback
+}
@@ -0,0 +1,188 @@
+package tglog
+
+import "fmt"
+
+// Fields is a set of structured key/value pairs attached to a log entry.
+type Fields map[string]interface{}
+
+// Event is a single in-progress log entry built up via chained field setters
+// and finished with Msg/Msgf. It is returned by Logger.Debug/Info/Warning/
+// Error/Fatal (and their FieldLogger equivalents) when called with no
+// arguments.
+type Event struct {
+	logger *Logger
+	level  LogLevel
+	fields map[string]interface{}
+}
+
+// newEvent creates an Event for level, pre-seeded with the given fields.
+func (l *Logger) newEvent(level LogLevel, seed map[string]interface{}) *Event {
+	fields := make(map[string]interface{}, len(seed))
+	for k, v := range seed {
+		fields[k] = v
+	}
+	return &Event{logger: l, level: level, fields: fields}
+}
+
+// dispatch implements the dual calling convention shared by Debug/Info/
+// Warning/Error/Fatal: called with no arguments it returns a chainable
+// Event; called with a format string (optionally followed by Printf args)
+// it formats and sends immediately, returning nil.
+func (l *Logger) dispatch(level LogLevel, v ...interface{}) *Event {
+	if len(v) == 0 {
+		return l.newEvent(level, nil)
+	}
+	format, ok := v[0].(string)
+	if !ok {
+		format = fmt.Sprint(v[0])
+	}
+	l.emit(level, fmt.Sprintf(format, v[1:]...), nil)
+	return nil
+}
+
+// Str adds a string field to the event.
+func (e *Event) Str(key, value string) *Event {
+	if e == nil {
+		return e
+	}
+	e.fields[key] = value
+	return e
+}
+
+// Int adds an int field to the event.
+func (e *Event) Int(key string, value int) *Event {
+	if e == nil {
+		return e
+	}
+	e.fields[key] = value
+	return e
+}
+
+// Bool adds a bool field to the event.
+func (e *Event) Bool(key string, value bool) *Event {
+	if e == nil {
+		return e
+	}
+	e.fields[key] = value
+	return e
+}
+
+// Float64 adds a float64 field to the event.
+func (e *Event) Float64(key string, value float64) *Event {
+	if e == nil {
+		return e
+	}
+	e.fields[key] = value
+	return e
+}
+
+// Err adds an "error" field to the event if err is non-nil; a nil err is a
+// no-op so it's safe to chain unconditionally.
+func (e *Event) Err(err error) *Event {
+	if e == nil || err == nil {
+		return e
+	}
+	e.fields["error"] = err.Error()
+	return e
+}
+
+// Any adds an arbitrary field to the event.
+func (e *Event) Any(key string, value interface{}) *Event {
+	if e == nil {
+		return e
+	}
+	e.fields[key] = value
+	return e
+}
+
+// Msg finishes the event, sending message along with any accumulated
+// fields.
+func (e *Event) Msg(message string) {
+	if e == nil {
+		return
+	}
+	e.logger.emit(e.level, message, e.fields)
+}
+
+// Msgf finishes the event, formatting message with Printf-style args.
+func (e *Event) Msgf(format string, args ...interface{}) {
+	if e == nil {
+		return
+	}
+	e.Msg(fmt.Sprintf(format, args...))
+}
+
+// LogWith logs a single event at level with fields attached, formatting
+// message with Printf-style args. It's a one-shot equivalent of
+// l.Info().Any(...).Msgf(...) for callers that already have their fields in
+// a map (e.g. forwarded from another system) rather than building them up
+// field by field.
+func (l *Logger) LogWith(level LogLevel, fields Fields, format string, args ...interface{}) {
+	merged := make(map[string]interface{}, len(fields))
+	for k, v := range fields {
+		merged[k] = v
+	}
+	l.emit(level, fmt.Sprintf(format, args...), merged)
+}
+
+// FieldLogger is a Logger bound to a fixed set of structured fields, created
+// via Logger.WithFields or FieldLogger.WithFields. Every event it produces
+// carries those fields in addition to any set on the event itself.
+type FieldLogger struct {
+	logger *Logger
+	fields map[string]interface{}
+}
+
+// WithFields returns a FieldLogger that inherits l's destination and
+// formatter but attaches fields to every message it logs.
+func (l *Logger) WithFields(fields Fields) *FieldLogger {
+	merged := make(map[string]interface{}, len(fields))
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return &FieldLogger{logger: l, fields: merged}
+}
+
+// WithFields returns a FieldLogger with fl's fields plus the given fields,
+// with fields taking precedence on key collisions.
+func (fl *FieldLogger) WithFields(fields Fields) *FieldLogger {
+	merged := make(map[string]interface{}, len(fl.fields)+len(fields))
+	for k, v := range fl.fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return &FieldLogger{logger: fl.logger, fields: merged}
+}
+
+func (fl *FieldLogger) dispatch(level LogLevel, v ...interface{}) *Event {
+	if len(v) == 0 {
+		return fl.logger.newEvent(level, fl.fields)
+	}
+	format, ok := v[0].(string)
+	if !ok {
+		format = fmt.Sprint(v[0])
+	}
+	fl.logger.emit(level, fmt.Sprintf(format, v[1:]...), fl.fields)
+	return nil
+}
+
+// Debug logs a debug-level event carrying fl's fields. See Logger.Debug for
+// the two calling conventions.
+func (fl *FieldLogger) Debug(v ...interface{}) *Event { return fl.dispatch(Debug, v...) }
+
+// Info logs an info-level event carrying fl's fields.
+func (fl *FieldLogger) Info(v ...interface{}) *Event { return fl.dispatch(Info, v...) }
+
+// Warning logs a warning-level event carrying fl's fields.
+func (fl *FieldLogger) Warning(v ...interface{}) *Event { return fl.dispatch(Warning, v...) }
+
+// Warn is an alias for Warning.
+func (fl *FieldLogger) Warn(v ...interface{}) *Event { return fl.dispatch(Warning, v...) }
+
+// Error logs an error-level event carrying fl's fields.
+func (fl *FieldLogger) Error(v ...interface{}) *Event { return fl.dispatch(Error, v...) }
+
+// Fatal logs a fatal-level event carrying fl's fields and exits the program.
+func (fl *FieldLogger) Fatal(v ...interface{}) *Event { return fl.dispatch(Fatal, v...) }
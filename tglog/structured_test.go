@@ -0,0 +1,121 @@
+package tglog
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFluentEventSendsFormattedMessage(t *testing.T) {
+	var receivedText, receivedParseMode string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg telegramMessage
+		_ = json.NewDecoder(r.Body).Decode(&msg)
+		receivedText = msg.Text
+		receivedParseMode = msg.ParseMode
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.BotToken = "test_token"
+	config.ChatID = "test_chat_id"
+	config.Async = false
+	config.Formatter = JSONFormatter{}
+	config.HTTPClient = &http.Client{Transport: &testTransport{originalTransport: http.DefaultTransport, testServer: server, t: t}}
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	logger.Info().Str("user", "alice").Int("attempt", 2).Msg("login failed")
+
+	if receivedText == "" {
+		t.Fatalf("expected a message to be sent")
+	}
+	if receivedParseMode != "" {
+		t.Errorf("expected JSON formatter to use an empty parse mode, got %q", receivedParseMode)
+	}
+	if !strings.Contains(receivedText, "login failed") || !strings.Contains(receivedText, "alice") {
+		t.Errorf("expected message to contain text and fields, got %q", receivedText)
+	}
+}
+
+func TestWithFieldsInheritsAcrossEvents(t *testing.T) {
+	config := DefaultConfig()
+	config.BotToken = "test_token"
+	config.ChatID = "test_chat_id"
+	config.Async = false
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	child := logger.WithFields(Fields{"request_id": "abc123"})
+	event := child.Info()
+	if event.fields["request_id"] != "abc123" {
+		t.Errorf("expected inherited field on event, got %v", event.fields)
+	}
+
+	grandchild := child.WithFields(Fields{"user": "bob"})
+	event2 := grandchild.Debug()
+	if event2.fields["request_id"] != "abc123" || event2.fields["user"] != "bob" {
+		t.Errorf("expected merged fields on grandchild event, got %v", event2.fields)
+	}
+}
+
+func TestLogWithSendsFormattedMessageAndFields(t *testing.T) {
+	var receivedText string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg telegramMessage
+		_ = json.NewDecoder(r.Body).Decode(&msg)
+		receivedText = msg.Text
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.BotToken = "test_token"
+	config.ChatID = "test_chat_id"
+	config.Async = false
+	config.Formatter = JSONFormatter{}
+	config.HTTPClient = &http.Client{Transport: &testTransport{originalTransport: http.DefaultTransport, testServer: server, t: t}}
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	logger.LogWith(Warning, Fields{"source": "webhook"}, "payload for %s rejected", "order-42")
+
+	if !strings.Contains(receivedText, "payload for order-42 rejected") {
+		t.Errorf("expected formatted message in output, got %q", receivedText)
+	}
+	if !strings.Contains(receivedText, "webhook") {
+		t.Errorf("expected field in output, got %q", receivedText)
+	}
+}
+
+func TestBackwardCompatiblePrintfStyleStillWorks(t *testing.T) {
+	config := DefaultConfig()
+	config.BotToken = "test_token"
+	config.ChatID = "test_chat_id"
+	config.Async = false
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	// MinLevel set above Fatal suppresses the actual send so this test
+	// needs no network mock, while still exercising dispatch's
+	// Printf-style branch.
+	logger.config.MinLevel = Fatal + 1
+	logger.Info("user %s logged in", "alice")
+}
@@ -0,0 +1,170 @@
+package tglog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"text/template"
+)
+
+// TemplateContext is the data made available to a Config.Templates /
+// DefaultTemplate text/template when rendering a log entry.
+type TemplateContext struct {
+	// App is Config.AppName.
+	App string
+	// Level is the lowercase level name, e.g. "warning".
+	Level string
+	// Emoji is the level's distinctive emoji (e.g. "⚠️" for Warning).
+	Emoji string
+	// Time is the timestamp, already formatted with Config.TimeFormat.
+	Time string
+	// Message is the formatted log message.
+	Message string
+	// Fields holds any structured key/value pairs attached to the entry.
+	Fields map[string]interface{}
+	// FieldsJSON is Fields marshaled to a JSON object, for templates that
+	// want to embed it verbatim (e.g. inside a ```json fenced block).
+	FieldsJSON string
+	// Hostname is the machine hostname, resolved once at formatter
+	// construction time.
+	Hostname string
+	// PID is the current process ID.
+	PID int
+	// Caller is "file:line" of the first call frame outside package tglog.
+	Caller string
+}
+
+// TemplateFormatter renders entries using operator-supplied text/template
+// layouts, one per LogLevel plus a fallback default.
+type TemplateFormatter struct {
+	templates   map[LogLevel]*template.Template
+	defaultTmpl *template.Template
+	parseMode   string
+	hostname    string
+	pid         int
+}
+
+// NewTemplateFormatter parses templates and defaultTemplate, returning an
+// error describing the first parse failure encountered. parseMode defaults
+// to "HTML" if empty.
+func NewTemplateFormatter(templates map[LogLevel]string, defaultTemplate string, parseMode string) (*TemplateFormatter, error) {
+	if parseMode == "" {
+		parseMode = "HTML"
+	}
+
+	tf := &TemplateFormatter{
+		templates: make(map[LogLevel]*template.Template, len(templates)),
+		parseMode: parseMode,
+		pid:       os.Getpid(),
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		tf.hostname = hostname
+	}
+
+	for level, src := range templates {
+		tmpl, err := template.New(levelName(level)).Parse(src)
+		if err != nil {
+			return nil, fmt.Errorf("template for level %s: %w", levelName(level), err)
+		}
+		tf.templates[level] = tmpl
+	}
+
+	if defaultTemplate != "" {
+		tmpl, err := template.New("default").Parse(defaultTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("default template: %w", err)
+		}
+		tf.defaultTmpl = tmpl
+	}
+
+	return tf, nil
+}
+
+// Format implements Formatter.
+func (tf *TemplateFormatter) Format(e Entry) string {
+	tmpl := tf.templates[e.Level]
+	if tmpl == nil {
+		tmpl = tf.defaultTmpl
+	}
+	if tmpl == nil {
+		// No template configured for this level and no default: fall back
+		// to the plain message rather than dropping it silently.
+		return e.Message
+	}
+
+	fieldsJSON := "{}"
+	if len(e.Fields) > 0 {
+		if data, err := json.Marshal(e.Fields); err == nil {
+			fieldsJSON = string(data)
+		}
+	}
+
+	ctx := TemplateContext{
+		App:        e.AppName,
+		Level:      levelName(e.Level),
+		Emoji:      levelEmoji(e.Level),
+		Time:       e.Timestamp,
+		Message:    e.Message,
+		Fields:     e.Fields,
+		FieldsJSON: fieldsJSON,
+		Hostname:   tf.hostname,
+		PID:        tf.pid,
+		Caller:     e.Caller,
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, ctx); err != nil {
+		return e.Message
+	}
+	return buf.String()
+}
+
+// ParseMode implements Formatter.
+func (tf *TemplateFormatter) ParseMode() string {
+	return tf.parseMode
+}
+
+// levelEmoji returns just the emoji for level, without the bracketed prefix
+// getLogLevelPrefix produces.
+func levelEmoji(level LogLevel) string {
+	switch level {
+	case Debug:
+		return "🔍"
+	case Info:
+		return "ℹ️"
+	case Warning:
+		return "⚠️"
+	case Error:
+		return "❌"
+	case Fatal:
+		return "💀"
+	default:
+		return "❓"
+	}
+}
+
+// callerInfo walks the call stack from its caller and returns "file:line"
+// for the first frame outside package tglog, or "" if none is found.
+func callerInfo() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.Contains(frame.Function, "/tglog.") && !strings.HasPrefix(frame.Function, "tglog.") {
+			idx := strings.LastIndexByte(frame.File, '/')
+			file := frame.File
+			if idx >= 0 {
+				file = frame.File[idx+1:]
+			}
+			return fmt.Sprintf("%s:%d", file, frame.Line)
+		}
+		if !more {
+			break
+		}
+	}
+	return ""
+}
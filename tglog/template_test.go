@@ -0,0 +1,64 @@
+package tglog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTemplateFormatterRendersPerLevel(t *testing.T) {
+	tf, err := NewTemplateFormatter(
+		map[LogLevel]string{Error: "{{.Emoji}} *{{.App}}* {{.Level}} - {{.Message}}"},
+		"{{.Level}}: {{.Message}}",
+		"",
+	)
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+
+	errMsg := tf.Format(Entry{Level: Error, AppName: "svc", Message: "boom"})
+	if !strings.Contains(errMsg, "svc") || !strings.Contains(errMsg, "boom") {
+		t.Errorf("expected level-specific template to render app and message, got %q", errMsg)
+	}
+
+	infoMsg := tf.Format(Entry{Level: Info, Message: "started"})
+	if infoMsg != "info: started" {
+		t.Errorf("expected default template for levels without an override, got %q", infoMsg)
+	}
+
+	if tf.ParseMode() != "HTML" {
+		t.Errorf("expected default parse mode HTML, got %q", tf.ParseMode())
+	}
+}
+
+func TestTemplateFormatterIncludesFieldsJSON(t *testing.T) {
+	tf, err := NewTemplateFormatter(nil, `{{.Message}}{{if .Fields}} {{.FieldsJSON}}{{end}}`, "MarkdownV2")
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+
+	msg := tf.Format(Entry{Message: "hi", Fields: map[string]interface{}{"k": "v"}})
+	if !strings.Contains(msg, `"k":"v"`) {
+		t.Errorf("expected FieldsJSON to include the field, got %q", msg)
+	}
+	if tf.ParseMode() != "MarkdownV2" {
+		t.Errorf("expected configured parse mode to be respected, got %q", tf.ParseMode())
+	}
+}
+
+func TestNewTemplateFormatterRejectsInvalidSyntax(t *testing.T) {
+	_, err := NewTemplateFormatter(map[LogLevel]string{Debug: "{{.Message"}, "", "")
+	if err == nil {
+		t.Fatalf("expected a parse error for malformed template")
+	}
+}
+
+func TestConfigTemplatesValidatedAtNew(t *testing.T) {
+	config := DefaultConfig()
+	config.BotToken = "test_token"
+	config.ChatID = "test_chat_id"
+	config.DefaultTemplate = "{{.Message"
+
+	if _, err := New(config); err == nil {
+		t.Fatalf("expected New to reject an invalid template")
+	}
+}
@@ -2,13 +2,13 @@
 package tglog
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
+	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 )
 
 // LogLevel represents the severity level of a log message
@@ -45,6 +45,74 @@ type Config struct {
 	TimeFormat string
 	// HTTPClient allows setting a custom HTTP client
 	HTTPClient *http.Client
+	// Formatter renders a log Entry into the text sent to Telegram and
+	// chooses the parse_mode used for it (defaults to HTMLFormatter, which
+	// reproduces the original emoji/HTML formatting). Takes precedence over
+	// Templates/DefaultTemplate when explicitly set.
+	Formatter Formatter
+	// Templates maps a LogLevel to a text/template source string used to
+	// render messages at that level. Levels without an entry fall back to
+	// DefaultTemplate. Ignored if Formatter is set.
+	Templates map[LogLevel]string
+	// DefaultTemplate is the text/template source used for any level not
+	// present in Templates. Ignored if Formatter is set.
+	DefaultTemplate string
+	// TemplateParseMode is the Telegram parse_mode used when Templates or
+	// DefaultTemplate is set (defaults to "HTML").
+	TemplateParseMode string
+	// Sinks are additional destinations entries fan out to alongside the
+	// primary Telegram chat; see Logger.AddSink to register one at runtime.
+	Sinks []SinkSpec
+	// EnvLookup resolves environment variables for Simple/WithEnv/
+	// SecureWithEnv (defaults to LookupEnv, i.e. os.LookupEnv). Those three
+	// take no Config argument and always build their own internally, so this
+	// field only matters to code that constructs a Config and calls getEnv
+	// directly; it replaces what used to be a hard-coded chain down to
+	// os.LookupEnv.
+	EnvLookup func(string) (string, bool)
+	// SpoolDir, if set, persists messages that failed delivery after
+	// retries to a file in this directory, replayed once at startup by New.
+	// Leave empty to drop undeliverable messages instead.
+	SpoolDir string
+	// RateLimit overrides the token-bucket rates the sender uses to stay
+	// under Telegram's flood limits. Zero fields fall back to defaults
+	// approximating Telegram's own limits.
+	RateLimit RateLimit
+	// MaxRetries is how many times a failed send is retried (429s honor
+	// Telegram's retry_after; other errors use exponential backoff with
+	// jitter) before the message is spooled or dropped. Zero means "use the
+	// default of 3"; there is currently no way to disable retries entirely.
+	MaxRetries int
+	// FlushInterval is how long processQueue waits for more same-level
+	// messages to arrive before flushing a coalesced batch. Defaults to
+	// 500ms; only meaningful when Async is true.
+	FlushInterval time.Duration
+	// MaxBatchBytes caps how large a coalesced batch may grow before it's
+	// flushed, keeping it safely under Telegram's 4096-character message
+	// limit. Defaults to 3500.
+	MaxBatchBytes int
+	// BotTokenFile, if set, is read once at New and its trimmed contents used
+	// as BotToken, so the token can be mounted from a Kubernetes or Docker
+	// secret instead of baked into Config or the environment. Ignored if
+	// SecretProvider is set.
+	BotTokenFile string
+	// ChatIDFile is BotTokenFile's counterpart for ChatID.
+	ChatIDFile string
+	// SecretProvider, if set, resolves BotToken and ChatID at New and takes
+	// precedence over BotTokenFile/ChatIDFile and the literal fields. New
+	// also starts a background goroutine that re-resolves credentials from
+	// it every SecretRefreshInterval, so a rotated token is picked up
+	// without restarting the process.
+	SecretProvider SecretProvider
+	// SecretRefreshInterval is how often SecretProvider is polled for
+	// updated credentials. Zero means "use the default of 5 minutes"; only
+	// meaningful when SecretProvider is set.
+	SecretRefreshInterval time.Duration
+	// AllowedUserIDs, if non-empty, restricts StartInteraction to these
+	// Telegram user IDs; anyone else's commands are silently ignored.
+	// StartInteractive does not read it; it uses only the AuthChecker in
+	// the InteractionConfig passed to it.
+	AllowedUserIDs []int64
 }
 
 // Logger represents a Telegram logger instance
@@ -55,13 +123,45 @@ type Logger struct {
 	wg         sync.WaitGroup
 	mu         sync.Mutex
 	closed     bool
+	sender     *sender
+
+	// levelMu guards config.MinLevel, which can be changed at runtime by
+	// interactive commands.
+	levelMu sync.RWMutex
+
+	// muteUntil is a UnixNano timestamp set by the /mute command; outbound
+	// sends are suppressed while time.Now() is before it. Zero means unmuted.
+	muteUntil int64
+
+	// sinksMu guards sinks, which can grow at runtime via AddSink.
+	sinksMu sync.RWMutex
+	sinks   []SinkSpec
+
+	// interactiveMu guards the interactive command state below. It is
+	// deliberately separate from mu: emit holds mu across a blocking
+	// msgQueue send for backpressure, and interactive commands like /stats
+	// and /status must stay responsive (reading the ring buffer, router,
+	// and authChecker) even while that send is stalled on a slow or down
+	// Telegram.
+	interactiveMu sync.RWMutex
+	interactive   *InteractionConfig
+	router        *CommandRouter
+	authChecker   AuthChecker
+	ring          []string
+	ringCap       int
+
+	// secretRefreshStop, when non-nil, signals the SecretProvider refresh
+	// goroutine started by New to exit; closed by Close.
+	secretRefreshStop chan struct{}
+	secretRefreshWG   sync.WaitGroup
 }
 
 // message represents a log message to be sent to Telegram
 type message struct {
-	level   LogLevel
-	content string
-	time    time.Time
+	level     LogLevel
+	content   string
+	parseMode string
+	time      time.Time
 }
 
 // telegramMessage represents the JSON structure for a Telegram message
@@ -79,11 +179,16 @@ func DefaultConfig() Config {
 		DisableColors: false,
 		TimeFormat:    "2006-01-02 15:04:05",
 		HTTPClient:    &http.Client{Timeout: 10 * time.Second},
+		EnvLookup:     LookupEnv,
 	}
 }
 
 // New creates a new Logger with the given configuration
 func New(config Config) (*Logger, error) {
+	if err := resolveSecrets(&config); err != nil {
+		return nil, err
+	}
+
 	if config.BotToken == "" {
 		return nil, fmt.Errorf("bot token is required")
 	}
@@ -99,60 +204,228 @@ func New(config Config) (*Logger, error) {
 		config.TimeFormat = "2006-01-02 15:04:05"
 	}
 
+	if len(config.Templates) > 0 || config.DefaultTemplate != "" {
+		tf, err := NewTemplateFormatter(config.Templates, config.DefaultTemplate, config.TemplateParseMode)
+		if err != nil {
+			return nil, fmt.Errorf("tglog: invalid template: %w", err)
+		}
+		if config.Formatter == nil {
+			config.Formatter = tf
+		}
+	}
+
+	if config.Formatter == nil {
+		config.Formatter = HTMLFormatter{Colored: !config.DisableColors}
+	}
+
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = defaultFlushInterval
+	}
+	if config.MaxBatchBytes <= 0 {
+		config.MaxBatchBytes = defaultMaxBatchBytes
+	}
+
 	logger := &Logger{
 		config:     config,
 		httpClient: config.HTTPClient,
+		sender:     newSender(config),
 	}
+	logger.sender.replaySpool()
 
 	if config.Async {
 		logger.msgQueue = make(chan message, 100)
 		go logger.processQueue()
 	}
 
+	logger.sinks = append(logger.sinks, config.Sinks...)
+
+	if config.SecretProvider != nil {
+		logger.startSecretRefresh()
+	}
+
 	return logger, nil
 }
 
-// Close waits for all async messages to be sent and closes the logger
+// Stats returns delivery counters since the logger was created: cumulative
+// sent, failed and dropped counts, and the number of messages currently
+// queued in the disk spool.
+func (l *Logger) Stats() Stats {
+	return l.sender.stats()
+}
+
+// Close waits for all async messages to be sent and closes the logger. Safe
+// to call more than once; only the first call tears anything down.
 func (l *Logger) Close() {
 	l.mu.Lock()
-	defer l.mu.Unlock()
+	alreadyClosed := l.closed
+	l.closed = true
+	if l.config.Async && !alreadyClosed {
+		close(l.msgQueue)
+	}
+	l.mu.Unlock()
 
-	if !l.config.Async || l.closed {
-		return
+	if !alreadyClosed && l.secretRefreshStop != nil {
+		close(l.secretRefreshStop)
+		l.secretRefreshWG.Wait()
 	}
 
-	l.closed = true
-	close(l.msgQueue)
 	l.wg.Wait()
+	l.closeSinks()
 }
 
-// processQueue handles asynchronous message sending
+const (
+	// defaultFlushInterval is used when Config.FlushInterval is unset: how
+	// long processQueue waits for more same-level messages to arrive before
+	// flushing a batch.
+	defaultFlushInterval = 500 * time.Millisecond
+	// defaultMaxBatchBytes is used when Config.MaxBatchBytes is unset: it
+	// keeps a coalesced batch safely under Telegram's 4096-character message
+	// limit.
+	defaultMaxBatchBytes = 3500
+)
+
+// processQueue drains msgQueue, coalescing consecutive same-level entries
+// that arrive within Config.FlushInterval into a single Telegram message
+// (subject to Config.MaxBatchBytes) to cut down on API calls under bursty
+// load.
 func (l *Logger) processQueue() {
+	flushInterval := l.config.FlushInterval
+	maxBatchBytes := l.config.MaxBatchBytes
+
 	for msg := range l.msgQueue {
-		l.sendMessage(msg.level, msg.content)
+		batch := []message{msg}
+		size := len(msg.content)
+		timer := time.NewTimer(flushInterval)
+
+	drain:
+		for {
+			select {
+			case next, ok := <-l.msgQueue:
+				if !ok {
+					break drain
+				}
+				if next.level != msg.level || size+len(next.content)+1 > maxBatchBytes {
+					l.flushBatch(batch)
+					batch = []message{next}
+					msg = next
+					size = len(next.content)
+					if !timer.Stop() {
+						<-timer.C
+					}
+					timer.Reset(flushInterval)
+					continue
+				}
+				batch = append(batch, next)
+				size += len(next.content) + 1
+			case <-timer.C:
+				break drain
+			}
+		}
+		timer.Stop()
+		l.flushBatch(batch)
+	}
+}
+
+// flushBatch sends a coalesced batch as one message (joined by newlines,
+// preserving queue order) and releases the WaitGroup for each message it
+// contains. If the joined text still exceeds Config.MaxBatchBytes (a single
+// oversized message, or several that together crossed the cap before
+// processQueue noticed), it's split into multiple sends on line boundaries.
+// The batch's full log-call count is attributed to the first send only, so
+// Stats aren't inflated by the split; a failure in a later chunk is
+// therefore not separately reflected in Stats.Failed/Dropped.
+//
+// A Fatal batch only exits the process after its last chunk is sent, so a
+// split crash log isn't truncated by an early os.Exit(1).
+func (l *Logger) flushBatch(batch []message) {
+	texts := make([]string, len(batch))
+	for i, m := range batch {
+		texts[i] = m.content
+	}
+	joined := strings.Join(texts, "\n")
+
+	chunks := splitOnLines(joined, l.config.MaxBatchBytes)
+	count := len(batch)
+	for i, chunk := range chunks {
+		level := batch[0].level
+		if level == Fatal && i != len(chunks)-1 {
+			level = Error
+		}
+		l.sender.deliver(level, chunk, batch[0].parseMode, count)
+		count = 0
+	}
+	for range batch {
 		l.wg.Done()
 	}
 }
 
-// log sends a log message to Telegram
-func (l *Logger) log(level LogLevel, format string, args ...interface{}) {
-	if level < l.config.MinLevel {
-		return
+// splitOnLines breaks text into chunks of at most maxBytes, splitting only
+// on line boundaries so multi-line content is never torn mid-line. A single
+// line longer than maxBytes is hard-split on a rune boundary as a last
+// resort, so multi-byte UTF-8 characters are never cut in half.
+func splitOnLines(text string, maxBytes int) []string {
+	if len(text) <= maxBytes {
+		return []string{text}
 	}
 
-	content := fmt.Sprintf(format, args...)
-	now := time.Now()
+	var chunks []string
+	var current strings.Builder
+	for _, line := range strings.Split(text, "\n") {
+		if current.Len() > 0 && current.Len()+1+len(line) > maxBytes {
+			chunks = append(chunks, current.String())
+			current.Reset()
+		}
+		for len(line) > maxBytes {
+			cut := maxBytes
+			for cut > 0 && !utf8.RuneStart(line[cut]) {
+				cut--
+			}
+			if cut == 0 {
+				cut = maxBytes
+			}
+			chunks = append(chunks, line[:cut])
+			line = line[cut:]
+		}
+		if current.Len() > 0 {
+			current.WriteByte('\n')
+		}
+		current.WriteString(line)
+	}
+	if current.Len() > 0 {
+		chunks = append(chunks, current.String())
+	}
+	return chunks
+}
 
-	// Format message with app name, timestamp, and level prefix
-	prefix := getLogLevelPrefix(level, !l.config.DisableColors)
-	timestamp := now.Format(l.config.TimeFormat)
+// emit formats content (and any structured fields) through the configured
+// Formatter and queues or sends the result.
+func (l *Logger) emit(level LogLevel, content string, fields map[string]interface{}) {
+	l.levelMu.RLock()
+	minLevel := l.config.MinLevel
+	l.levelMu.RUnlock()
+	if level < minLevel {
+		return
+	}
+	if level != Fatal && l.isMuted() {
+		return
+	}
 
-	appName := ""
-	if l.config.AppName != "" {
-		appName = fmt.Sprintf("<b>[%s]</b> ", l.config.AppName)
+	now := time.Now()
+	entry := Entry{
+		Level:     level,
+		AppName:   l.config.AppName,
+		Timestamp: now.Format(l.config.TimeFormat),
+		Message:   content,
+		Fields:    fields,
+	}
+	if _, ok := l.config.Formatter.(*TemplateFormatter); ok {
+		entry.Caller = callerInfo()
 	}
 
-	formattedMsg := fmt.Sprintf("%s%s %s - %s", appName, prefix, timestamp, content)
+	formattedMsg := l.config.Formatter.Format(entry)
+	parseMode := l.config.Formatter.ParseMode()
+	l.pushRing(formattedMsg)
+	l.dispatchToSinks(entry)
 
 	if l.config.Async && !l.closed {
 		l.mu.Lock()
@@ -161,47 +434,34 @@ func (l *Logger) log(level LogLevel, format string, args ...interface{}) {
 		if !l.closed {
 			l.wg.Add(1)
 			l.msgQueue <- message{
-				level:   level,
-				content: formattedMsg,
-				time:    now,
+				level:     level,
+				content:   formattedMsg,
+				parseMode: parseMode,
+				time:      now,
 			}
 			return
 		}
 	}
 
-	l.sendMessage(level, formattedMsg)
+	l.sendMessage(level, formattedMsg, parseMode)
 }
 
-// sendMessage sends a message to Telegram
-func (l *Logger) sendMessage(level LogLevel, content string) {
-	apiURL := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", l.config.BotToken)
-
-	msg := telegramMessage{
-		ChatID:    l.config.ChatID,
-		Text:      content,
-		ParseMode: "HTML",
-	}
-
-	jsonData, err := json.Marshal(msg)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "tglog: failed to marshal message: %v\n", err)
-		return
-	}
-
-	resp, err := l.httpClient.Post(apiURL, "application/json", bytes.NewBuffer(jsonData))
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "tglog: failed to send message: %v\n", err)
-		return
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode >= 400 {
-		fmt.Fprintf(os.Stderr, "tglog: telegram API returned error status: %d\n", resp.StatusCode)
-	}
+// sendMessage delivers a single message to Telegram via the logger's
+// sender, which applies rate limiting, retries, and disk spooling.
+func (l *Logger) sendMessage(level LogLevel, content string, parseMode string) {
+	l.sender.deliver(level, content, parseMode, 1)
+}
 
-	// If Fatal, exit the program
-	if level == Fatal {
-		os.Exit(1)
+// sendReply delivers an interactive command's reply to chatID, the chat
+// that issued the command, rather than Config.ChatID — chatID differs from
+// Config.ChatID whenever commands are accepted from another chat (no
+// AuthChecker, a UserWhitelist, or a ChatWhitelist of several chats). A
+// reply that fails after retries is logged to stderr and dropped, not
+// spooled; unlike a log message, there's no later moment it becomes worth
+// redelivering.
+func (l *Logger) sendReply(chatID, content, parseMode string) {
+	if err := l.sender.replyToChat(chatID, content, parseMode); err != nil {
+		fmt.Fprintf(os.Stderr, "tglog: failed to send command reply: %v\n", err)
 	}
 }
 
@@ -250,32 +510,38 @@ func getLogLevelPrefix(level LogLevel, colored bool) string {
 	return fmt.Sprintf("%s [%s]", emoji, prefix)
 }
 
-// Debug logs a debug message
-func (l *Logger) Debug(format string, args ...interface{}) {
-	l.log(Debug, format, args...)
+// Debug logs a debug message. Called with no arguments it returns a
+// chainable *Event (logger.Debug().Str("k", "v").Msg("message")); called with
+// a format string and args it behaves exactly like the original
+// Printf-style API and sends immediately.
+func (l *Logger) Debug(v ...interface{}) *Event {
+	return l.dispatch(Debug, v...)
 }
 
-// Info logs an info message
-func (l *Logger) Info(format string, args ...interface{}) {
-	l.log(Info, format, args...)
+// Info logs an info message. See Debug for the two calling conventions.
+func (l *Logger) Info(v ...interface{}) *Event {
+	return l.dispatch(Info, v...)
 }
 
-// Warning logs a warning message
-func (l *Logger) Warning(format string, args ...interface{}) {
-	l.log(Warning, format, args...)
+// Warning logs a warning message. See Debug for the two calling conventions.
+func (l *Logger) Warning(v ...interface{}) *Event {
+	return l.dispatch(Warning, v...)
 }
 
 // Warn is an alias for Warning
-func (l *Logger) Warn(format string, args ...interface{}) {
-	l.Warning(format, args...)
+func (l *Logger) Warn(v ...interface{}) *Event {
+	return l.dispatch(Warning, v...)
 }
 
-// Error logs an error message
-func (l *Logger) Error(format string, args ...interface{}) {
-	l.log(Error, format, args...)
+// Error logs an error message. See Debug for the two calling conventions.
+func (l *Logger) Error(v ...interface{}) *Event {
+	return l.dispatch(Error, v...)
 }
 
-// Fatal logs a fatal message and exits the program
-func (l *Logger) Fatal(format string, args ...interface{}) {
-	l.log(Fatal, format, args...)
+// Fatal logs a fatal message and exits the program. See Debug for the two
+// calling conventions; exiting happens once delivery of the message has been
+// attempted, whether or not it succeeded, so it still applies when
+// fluent-chained with .Msg().
+func (l *Logger) Fatal(v ...interface{}) *Event {
+	return l.dispatch(Fatal, v...)
 }
@@ -6,8 +6,10 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
 	"time"
+	"unicode/utf8"
 )
 
 // TestLogger tests the basic functionality of the logger
@@ -151,3 +153,131 @@ func (tt *testTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	// For other requests, use the original transport
 	return tt.originalTransport.RoundTrip(req)
 }
+
+// TestNewDefaultsFlushIntervalAndMaxBatchBytes verifies New fills in the
+// batching knobs from their package defaults when left unset.
+func TestNewDefaultsFlushIntervalAndMaxBatchBytes(t *testing.T) {
+	config := DefaultConfig()
+	config.BotToken = "test_token"
+	config.ChatID = "test_chat_id"
+	config.Async = false
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	if logger.config.FlushInterval != defaultFlushInterval {
+		t.Errorf("expected FlushInterval to default to %s, got %s", defaultFlushInterval, logger.config.FlushInterval)
+	}
+	if logger.config.MaxBatchBytes != defaultMaxBatchBytes {
+		t.Errorf("expected MaxBatchBytes to default to %d, got %d", defaultMaxBatchBytes, logger.config.MaxBatchBytes)
+	}
+}
+
+// TestSplitOnLinesRespectsLineBoundaries checks that splitOnLines never tears
+// a line in two unless that single line already exceeds maxBytes on its own.
+func TestSplitOnLinesRespectsLineBoundaries(t *testing.T) {
+	text := strings.Join([]string{"short one", "short two", "short three", "short four"}, "\n")
+
+	chunks := splitOnLines(text, 20)
+	for _, c := range chunks {
+		if len(c) > 20 {
+			t.Errorf("chunk exceeds maxBytes: %q (%d bytes)", c, len(c))
+		}
+	}
+	if rejoined := strings.Join(chunks, "\n"); rejoined != text {
+		t.Errorf("splitting and rejoining lost or reordered content:\ngot:  %q\nwant: %q", rejoined, text)
+	}
+}
+
+// TestSplitOnLinesHardSplitsAnOversizedSingleLine checks the fallback for a
+// single line longer than maxBytes, which can't be split on a line boundary.
+func TestSplitOnLinesHardSplitsAnOversizedSingleLine(t *testing.T) {
+	line := strings.Repeat("x", 50)
+
+	chunks := splitOnLines(line, 20)
+	if len(chunks) != 3 {
+		t.Fatalf("expected 3 chunks of at most 20 bytes each, got %d: %v", len(chunks), chunks)
+	}
+	if rejoined := strings.Join(chunks, ""); rejoined != line {
+		t.Errorf("hard-split chunks don't reconstruct the original line:\ngot:  %q\nwant: %q", rejoined, line)
+	}
+}
+
+// TestSplitOnLinesHardSplitDoesNotCutARuneInHalf checks that the hard-split
+// fallback never produces a chunk ending mid-UTF-8-rune.
+func TestSplitOnLinesHardSplitDoesNotCutARuneInHalf(t *testing.T) {
+	line := strings.Repeat("€", 20) // each € is 3 bytes in UTF-8
+
+	chunks := splitOnLines(line, 10)
+	for _, c := range chunks {
+		if !utf8.ValidString(c) {
+			t.Errorf("chunk is not valid UTF-8: %q", c)
+		}
+	}
+	if rejoined := strings.Join(chunks, ""); rejoined != line {
+		t.Errorf("hard-split chunks don't reconstruct the original line:\ngot:  %q\nwant: %q", rejoined, line)
+	}
+}
+
+// TestFlushBatchSplitsOversizedBatchOnLineBoundaries checks that flushBatch
+// respects a small MaxBatchBytes by splitting an oversized coalesced batch
+// into multiple sends, attributing the whole batch's log-call count to only
+// the first send so Stats aren't inflated by the split.
+func TestFlushBatchSplitsOversizedBatchOnLineBoundaries(t *testing.T) {
+	var mu sync.Mutex
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var msg telegramMessage
+		json.Unmarshal(body, &msg)
+		mu.Lock()
+		received = append(received, msg.Text)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.BotToken = "test_token"
+	config.ChatID = "test_chat_id"
+	config.Async = false
+	config.MaxBatchBytes = 20
+	config.HTTPClient = &http.Client{Transport: &testTransport{originalTransport: http.DefaultTransport, testServer: server, t: t}}
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	lines := []string{"first line", "second line", "third line"}
+	batch := make([]message, len(lines))
+	for i, line := range lines {
+		batch[i] = message{level: Info, content: line, parseMode: "HTML"}
+	}
+	logger.wg.Add(len(batch))
+	logger.flushBatch(batch)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) < 2 {
+		t.Fatalf("expected the oversized batch to be split into multiple sends, got %d: %v", len(received), received)
+	}
+	for _, text := range received {
+		if len(text) > config.MaxBatchBytes {
+			t.Errorf("received message exceeds MaxBatchBytes: %q (%d bytes)", text, len(text))
+		}
+	}
+	if rejoined := strings.Join(received, "\n"); rejoined != strings.Join(lines, "\n") {
+		t.Errorf("split sends don't reconstruct the original batch:\ngot:  %q\nwant: %q", rejoined, strings.Join(lines, "\n"))
+	}
+
+	stats := logger.Stats()
+	if stats.Sent != int64(len(lines)) {
+		t.Errorf("expected Stats.Sent to count %d original log calls despite the split, got %d", len(lines), stats.Sent)
+	}
+}
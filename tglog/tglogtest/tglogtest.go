@@ -0,0 +1,219 @@
+// Package tglogtest provides a deterministic, in-process fake of the
+// Telegram Bot API for testing code built on tglog. tglog's own tests do
+// not use it; they redirect requests with their own testTransport mock.
+package tglogtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nshiffer/tglog"
+)
+
+// SentMessage is a captured call to the fake /sendMessage endpoint.
+type SentMessage struct {
+	ChatID    string
+	Text      string
+	ParseMode string
+}
+
+// FaultMode selects the failure behavior FakeTelegram.SetFault injects into
+// subsequent /sendMessage calls.
+type FaultMode int
+
+const (
+	// FaultNone delivers requests normally.
+	FaultNone FaultMode = iota
+	// FaultDrop closes the connection without responding, simulating a
+	// network partition.
+	FaultDrop
+	// FaultDelay sleeps for Fault.Delay before responding normally.
+	FaultDelay
+	// FaultRetryAfter responds 429 with parameters.retry_after set to
+	// Fault.RetryAfterSeconds, exercising Telegram's flood-control path.
+	FaultRetryAfter
+	// Fault5xx responds with Fault.StatusCode (defaulting to 500).
+	Fault5xx
+)
+
+// Fault describes a single fault-injection behavior.
+type Fault struct {
+	Mode              FaultMode
+	Delay             time.Duration
+	RetryAfterSeconds int
+	StatusCode        int
+}
+
+// FakeTelegram is an in-process stand-in for the Telegram Bot API, exposing
+// /bot<token>/sendMessage, /getUpdates, and /setWebhook.
+type FakeTelegram struct {
+	Server *httptest.Server
+
+	mu    sync.Mutex
+	sent  []SentMessage
+	fault Fault
+}
+
+// New starts a FakeTelegram server, automatically closed via t.Cleanup.
+func New(t *testing.T) *FakeTelegram {
+	t.Helper()
+	ft := &FakeTelegram{}
+	ft.Server = httptest.NewServer(http.HandlerFunc(ft.handle))
+	t.Cleanup(ft.Server.Close)
+	return ft
+}
+
+func (ft *FakeTelegram) handle(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/sendMessage"):
+		ft.handleSendMessage(w, r)
+	case strings.HasSuffix(r.URL.Path, "/getUpdates"):
+		ft.handleGetUpdates(w, r)
+	case strings.HasSuffix(r.URL.Path, "/setWebhook"):
+		ft.handleSetWebhook(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (ft *FakeTelegram) handleSendMessage(w http.ResponseWriter, r *http.Request) {
+	ft.mu.Lock()
+	fault := ft.fault
+	ft.mu.Unlock()
+
+	switch fault.Mode {
+	case FaultDrop:
+		if hj, ok := w.(http.Hijacker); ok {
+			if conn, _, err := hj.Hijack(); err == nil {
+				conn.Close()
+				return
+			}
+		}
+		return
+	case FaultDelay:
+		time.Sleep(fault.Delay)
+	case FaultRetryAfter:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprintf(w, `{"ok":false,"error_code":429,"description":"Too Many Requests: retry later","parameters":{"retry_after":%d}}`, fault.RetryAfterSeconds)
+		return
+	case Fault5xx:
+		code := fault.StatusCode
+		if code == 0 {
+			code = http.StatusInternalServerError
+		}
+		w.WriteHeader(code)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	var payload struct {
+		ChatID    string `json:"chat_id"`
+		Text      string `json:"text"`
+		ParseMode string `json:"parse_mode"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	ft.mu.Lock()
+	ft.sent = append(ft.sent, SentMessage{ChatID: payload.ChatID, Text: payload.Text, ParseMode: payload.ParseMode})
+	ft.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+}
+
+func (ft *FakeTelegram) handleGetUpdates(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"ok":true,"result":[]}`))
+}
+
+func (ft *FakeTelegram) handleSetWebhook(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"ok":true,"result":true}`))
+}
+
+// SetFault installs f, applying it to every /sendMessage call until cleared
+// or replaced.
+func (ft *FakeTelegram) SetFault(f Fault) {
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+	ft.fault = f
+}
+
+// ClearFault restores normal /sendMessage behavior.
+func (ft *FakeTelegram) ClearFault() {
+	ft.SetFault(Fault{})
+}
+
+// Sent returns a snapshot of every message captured so far.
+func (ft *FakeTelegram) Sent() []SentMessage {
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+	out := make([]SentMessage, len(ft.sent))
+	copy(out, ft.sent)
+	return out
+}
+
+// AssertSent fails the test unless at least one captured message satisfies
+// matcher.
+func AssertSent(t *testing.T, ft *FakeTelegram, matcher func(SentMessage) bool) {
+	t.Helper()
+	for _, m := range ft.Sent() {
+		if matcher(m) {
+			return
+		}
+	}
+	t.Fatalf("tglogtest: no sent message matched; got %+v", ft.Sent())
+}
+
+// WithFakeTelegram starts a FakeTelegram and returns a tglog.Config already
+// wired to send to it instead of the real Telegram API.
+func WithFakeTelegram(t *testing.T) (tglog.Config, *FakeTelegram) {
+	t.Helper()
+	ft := New(t)
+
+	config := tglog.DefaultConfig()
+	config.BotToken = "test_token"
+	config.ChatID = "test_chat_id"
+	config.HTTPClient = &http.Client{Transport: &redirectTransport{target: ft.Server.URL}}
+	return config, ft
+}
+
+// redirectTransport rewrites requests bound for api.telegram.org to target,
+// the same technique as the testTransport in tglog's own tests.
+type redirectTransport struct {
+	target string
+}
+
+func (rt *redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !strings.Contains(req.URL.Host, "api.telegram.org") {
+		return http.DefaultTransport.RoundTrip(req)
+	}
+
+	newReq := req.Clone(req.Context())
+	parsed, err := req.URL.Parse(rt.target + req.URL.Path + "?" + req.URL.RawQuery)
+	if err != nil {
+		return nil, err
+	}
+	newReq.URL = parsed
+	newReq.Host = ""
+	return http.DefaultTransport.RoundTrip(newReq)
+}
@@ -0,0 +1,59 @@
+package tglogtest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nshiffer/tglog"
+)
+
+func TestWithFakeTelegramCapturesSentMessage(t *testing.T) {
+	config, ft := WithFakeTelegram(t)
+	config.Async = false
+
+	logger, err := tglog.New(config)
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	logger.Info("hello from a test")
+
+	AssertSent(t, ft, func(m SentMessage) bool {
+		return strings.Contains(m.Text, "hello from a test")
+	})
+}
+
+func TestFaultRetryAfterIsReturned(t *testing.T) {
+	config, ft := WithFakeTelegram(t)
+	config.Async = false
+	ft.SetFault(Fault{Mode: FaultRetryAfter, RetryAfterSeconds: 0})
+
+	logger, err := tglog.New(config)
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	// The logger retries a persistent 429 up to its retry limit and then
+	// gives up; it must never treat the 429 body as a successful send.
+	logger.Info("should trigger a 429")
+
+	if len(ft.Sent()) != 0 {
+		t.Errorf("expected the 429 response not to be recorded as a sent message, got %+v", ft.Sent())
+	}
+}
+
+func TestFault5xxDoesNotPanic(t *testing.T) {
+	config, ft := WithFakeTelegram(t)
+	config.Async = false
+	ft.SetFault(Fault{Mode: Fault5xx, StatusCode: 503})
+
+	logger, err := tglog.New(config)
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	logger.Error("downstream is unhappy")
+}